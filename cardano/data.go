@@ -7,6 +7,8 @@ import (
 
 	"github.com/blinklabs-io/gouroboros/ledger/common"
 	"github.com/ipfs/go-cid"
+
+	"github.com/functionally/nacatgunma/key"
 )
 
 type Datum struct {
@@ -78,3 +80,16 @@ func MetadataJSON(metadataKey uint, blockchain string, header string) ([]byte, e
 	}
 	return buf.Bytes(), nil
 }
+
+// KeyCredentialHash returns the hexadecimal Blake2b224 hash of an Ed25519 key, which is
+// the credential of the key on Cardano.
+func KeyCredentialHash(k key.Key) (string, error) {
+	keyType, pubBytes, err := key.PublicKeyFromDid(key.Did(k))
+	if err != nil {
+		return "", err
+	}
+	if keyType != key.Ed25519 {
+		return "", fmt.Errorf("only Ed25519 keys are Cardano credentials")
+	}
+	return hex.EncodeToString(common.Blake2b224Hash(pubBytes).Bytes()), nil
+}
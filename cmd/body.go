@@ -17,6 +17,8 @@ func BodyCmds() *cli.Command {
 		Subcommands: []*cli.Command{
 			bodyExportCmd(),
 			rdfCmd(),
+			rdfExportCmd(),
+			rdfValidateCmd(),
 			tgdhCmds(),
 		},
 	}
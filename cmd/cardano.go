@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 
 	"github.com/blinklabs-io/gouroboros/ledger/common"
@@ -29,12 +30,14 @@ func cardanoDatumCmd() *cli.Command {
 	var headerCid string
 	var script bool
 	var credential string
+	var keyFile string
+	var keyAlias string
 	var datumFile string
 
 	return &cli.Command{
 		Name:  "datum",
 		Usage: "Create datum for a tip.",
-		Flags: []cli.Flag{
+		Flags: append(signingKeyFlags(&keyFile, &keyAlias), []cli.Flag{
 			&cli.BoolFlag{
 				Name:        "script",
 				Required:    true,
@@ -43,8 +46,8 @@ func cardanoDatumCmd() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:        "credential-hash",
-				Required:    true,
-				Usage:       "Blake2b224 hash of the credential, in hexadecimal",
+				Required:    false,
+				Usage:       "Blake2b224 hash of the credential, in hexadecimal, instead of the hash of a signing key",
 				Destination: &credential,
 			},
 			&cli.StringFlag{
@@ -59,8 +62,12 @@ func cardanoDatumCmd() *cli.Command {
 				Usage:       "Output file for JSON-formatted datum",
 				Destination: &datumFile,
 			},
-		},
+		}...),
 		Action: func(*cli.Context) error {
+			credential, err := resolveCredential(script, credential, keyFile, keyAlias)
+			if err != nil {
+				return err
+			}
 			datum, err := cardano.NewDatum(script, credential, headerCid)
 			if err != nil {
 				return err
@@ -80,6 +87,8 @@ func cardanoInputsCmd() *cli.Command {
 	var headerCid string
 	var script bool
 	var credential string
+	var keyFile string
+	var keyAlias string
 	var datumFile string
 	var metadataKey uint
 	var redeemerFile string
@@ -89,7 +98,7 @@ func cardanoInputsCmd() *cli.Command {
 	return &cli.Command{
 		Name:  "inputs",
 		Usage: "Create inputs for a tip.",
-		Flags: []cli.Flag{
+		Flags: append(signingKeyFlags(&keyFile, &keyAlias), []cli.Flag{
 			&cli.UintFlag{
 				Name:        "metadata-key",
 				Value:       58312,
@@ -110,8 +119,8 @@ func cardanoInputsCmd() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:        "credential-hash",
-				Required:    true,
-				Usage:       "Blake2b224 hash of the credential, in hexadecimal",
+				Required:    false,
+				Usage:       "Blake2b224 hash of the credential, in hexadecimal, instead of the hash of a signing key",
 				Destination: &credential,
 			},
 			&cli.StringFlag{
@@ -138,8 +147,12 @@ func cardanoInputsCmd() *cli.Command {
 				Usage:       "Output file for JSON-formatted metadata",
 				Destination: &metadataFile,
 			},
-		},
+		}...),
 		Action: func(*cli.Context) error {
+			credential, err := resolveCredential(script, credential, keyFile, keyAlias)
+			if err != nil {
+				return err
+			}
 			datum, err := cardano.NewDatum(script, credential, headerCid)
 			if err != nil {
 				return err
@@ -305,3 +318,22 @@ func cardanoTipsCmd() *cli.Command {
 	}
 
 }
+
+// resolveCredential uses the credential hash if given, and otherwise the hash of the
+// signing key, so that the key issuing headers also controls the tip.
+func resolveCredential(script bool, credential string, keyFile string, keyAlias string) (string, error) {
+	if credential != "" {
+		if keyFile != "" || keyAlias != "" {
+			return "", fmt.Errorf("--credential-hash may not be specified with --key-file or --key")
+		}
+		return credential, nil
+	}
+	if script {
+		return "", fmt.Errorf("--credential-hash is required for script credentials")
+	}
+	k, err := readSigningKey(keyFile, keyAlias)
+	if err != nil {
+		return "", err
+	}
+	return cardano.KeyCredentialHash(k)
+}
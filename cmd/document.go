@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/functionally/nacatgunma/key"
+	"github.com/functionally/nacatgunma/tgdh"
+)
+
+func keyDidDocumentCmd() *cli.Command {
+
+	var domain string
+	var path string
+	var keyFiles cli.StringSlice
+	var keyAliases cli.StringSlice
+	var keyDids cli.StringSlice
+	var tgdhFiles cli.StringSlice
+	var services cli.StringSlice
+	var format string
+	var outputFile string
+
+	return &cli.Command{
+		Name:  "did-document",
+		Usage: "Create a DID document for hosting as a did:web, printing the DID.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "domain",
+				Required:    true,
+				Usage:       "The domain hosting the DID document, optionally with a port",
+				Destination: &domain,
+			},
+			&cli.StringFlag{
+				Name:        "path",
+				Value:       "",
+				Usage:       "The path of the DID document on the domain, if not in \"/.well-known/\"",
+				Destination: &path,
+			},
+			&cli.StringSliceFlag{
+				Name:        "key-file",
+				Usage:       "Input file for a private key that signs on behalf of the DID",
+				Destination: &keyFiles,
+			},
+			&cli.StringSliceFlag{
+				Name:        "key",
+				Usage:       "Alias of a key in the keyring that signs on behalf of the DID",
+				Destination: &keyAliases,
+			},
+			&cli.StringSliceFlag{
+				Name:        "key-did",
+				Usage:       "The did:key of a public key that signs on behalf of the DID",
+				Destination: &keyDids,
+			},
+			&cli.StringSliceFlag{
+				Name:        "tgdh-file",
+				Usage:       "Input file of a TGDH key whose public key is listed for key agreement",
+				Destination: &tgdhFiles,
+			},
+			&cli.StringSliceFlag{
+				Name:        "service",
+				Usage:       "A service endpoint as \"type=endpoint\", such as \"NacatgunmaIpfs=https://ipfs.example.org\" or \"NacatgunmaCardanoTip=addr1...\"",
+				Destination: &services,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Value:       "multikey",
+				Usage:       "The format of verification methods, either \"multikey\" or \"jwk\"",
+				Destination: &format,
+			},
+			&cli.StringFlag{
+				Name:        "output-file",
+				Value:       "did.json",
+				Usage:       "Output file for the DID document",
+				Destination: &outputFile,
+			},
+		},
+		Action: func(*cli.Context) error {
+			if format != "multikey" && format != "jwk" {
+				return fmt.Errorf("unsupported verification method format: %v", format)
+			}
+			did := key.DidWeb(domain, path)
+			doc := key.NewDidDocument(did, format == "jwk")
+			signers := keyDids.Value()
+			for _, keyFile := range keyFiles.Value() {
+				k, err := readPrivateKey(keyFile)
+				if err != nil {
+					return err
+				}
+				signers = append(signers, key.Did(k))
+			}
+			for _, keyAlias := range keyAliases.Value() {
+				signer, err := resolveDid(keyAlias)
+				if err != nil {
+					return err
+				}
+				signers = append(signers, signer)
+			}
+			if len(signers) == 0 {
+				return fmt.Errorf("at least one of --key-file, --key, or --key-did must be specified")
+			}
+			for _, signer := range signers {
+				err := doc.AddSigningKey(signer)
+				if err != nil {
+					return err
+				}
+			}
+			for _, tgdhFile := range tgdhFiles.Value() {
+				tgdhBytes, err := os.ReadFile(tgdhFile)
+				if err != nil {
+					return err
+				}
+				node, err := tgdh.UnmarshalJSON(tgdhBytes)
+				if err != nil {
+					return err
+				}
+				err = doc.AddKeyAgreementKey(node.Did())
+				if err != nil {
+					return err
+				}
+			}
+			for _, service := range services.Value() {
+				serviceType, endpoint, okay := strings.Cut(service, "=")
+				if !okay || serviceType == "" || endpoint == "" {
+					return fmt.Errorf("service must be \"type=endpoint\": %v", service)
+				}
+				doc.AddService(serviceType, endpoint)
+			}
+			docBytes, err := doc.Marshal()
+			if err != nil {
+				return err
+			}
+			err = os.WriteFile(outputFile, docBytes, 0644)
+			if err != nil {
+				return err
+			}
+			fmt.Println(did)
+			return nil
+		},
+	}
+
+}
+
+// ResolverFlags let every command verify signatures against pinned DID documents,
+// optionally without fetching did:web documents.
+func ResolverFlags() ([]cli.Flag, cli.BeforeFunc) {
+
+	var didDocuments cli.StringSlice
+	var offline bool
+
+	flags := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "did-document",
+			Usage:       "Input file of a DID document to use instead of resolving its DID",
+			Destination: &didDocuments,
+		},
+		&cli.BoolFlag{
+			Name:        "offline",
+			Value:       false,
+			Usage:       "Do not fetch did:web documents, so they must be given by --did-document",
+			Destination: &offline,
+		},
+	}
+	before := func(*cli.Context) error {
+		for _, didDocument := range didDocuments.Value() {
+			err := key.DefaultRegistry.PinFile(didDocument)
+			if err != nil {
+				return err
+			}
+		}
+		if offline {
+			key.DefaultRegistry.Disable("web")
+		}
+		return nil
+	}
+	return flags, before
+
+}
@@ -4,10 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/functionally/nacatgunma/header"
 	"github.com/functionally/nacatgunma/ipfs"
-	"github.com/functionally/nacatgunma/key"
 	"github.com/ipfs/go-cid"
 	"github.com/urfave/cli/v2"
 )
@@ -17,71 +17,44 @@ func HeaderCmds() *cli.Command {
 		Name:  "header",
 		Usage: "Header management subcommands",
 		Subcommands: []*cli.Command{
+			headerAssembleCmd(),
 			headerBuildCmd(),
+			headerDelegateCmd(),
 			headerExportCmd(),
+			headerPayloadCmd(),
 			headerVerifyCmd(),
 		},
 	}
 }
 
-func headerBuildCmd() *cli.Command {
+func headerAssembleCmd() *cli.Command {
 
-	var keyFile string
+	var payloadFile string
+	var issuer string
+	var signatureFile string
 	var headerFile string
-	var payload header.Payload
-	var body string
-	var accepts cli.StringSlice
-	var rejects cli.StringSlice
 
 	return &cli.Command{
-		Name:  "build",
-		Usage: "Build a block header.",
+		Name:  "assemble",
+		Usage: "Assemble a block header from a payload and a signature made elsewhere.",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:        "key-file",
+				Name:        "payload-file",
 				Required:    true,
-				Usage:       "Input file for private key",
-				Destination: &keyFile,
-			},
-			&cli.Int64Flag{
-				Name:        "version",
-				Value:       1,
-				Usage:       "Header version number",
-				Destination: &payload.Version,
-			},
-			&cli.StringFlag{
-				Name:        "schema",
-				Value:       "https://w3c.github.io/json-ld-cbor/",
-				Usage:       "Schema for the block body",
-				Destination: &payload.SchemaURI,
-			},
-			&cli.StringSliceFlag{
-				Name:        "accept",
-				Usage:       "Accept a CID as a parent block",
-				Destination: &accepts,
-			},
-			&cli.StringSliceFlag{
-				Name:        "reject",
-				Usage:       "Reject a CID as an ancestor block",
-				Destination: &rejects,
+				Usage:       "Input file for the header payload CBOR",
+				Destination: &payloadFile,
 			},
 			&cli.StringFlag{
-				Name:        "body",
+				Name:        "issuer",
 				Required:    true,
-				Usage:       "CID for the block body",
-				Destination: &body,
-			},
-			&cli.StringFlag{
-				Name:        "media-type",
-				Value:       "application/vnd.ipld.dag-cbor",
-				Usage:       "Media type for block body",
-				Destination: &payload.MediaType,
+				Usage:       "The DID or keyring alias of the issuer, such as a threshold group",
+				Destination: &issuer,
 			},
 			&cli.StringFlag{
-				Name:        "comment",
-				Value:       "",
-				Usage:       "Creator-supplied comment on the block",
-				Destination: &payload.Comment,
+				Name:        "signature-file",
+				Required:    true,
+				Usage:       "Input file for the signature of the payload",
+				Destination: &signatureFile,
 			},
 			&cli.StringFlag{
 				Name:        "header-file",
@@ -91,30 +64,134 @@ func headerBuildCmd() *cli.Command {
 			},
 		},
 		Action: func(*cli.Context) error {
-			k, err := key.ReadPrivateKey(keyFile)
+			payloadBytes, err := os.ReadFile(payloadFile)
 			if err != nil {
 				return err
 			}
-			bodyCid, err := cid.Parse(body)
+			payload, err := header.UnmarshalPayload(payloadBytes)
+			if err != nil {
+				return err
+			}
+			signature, err := os.ReadFile(signatureFile)
+			if err != nil {
+				return err
+			}
+			issuer, err = resolveDid(issuer)
+			if err != nil {
+				return err
+			}
+			hdr, err := payload.Assemble(issuer, signature)
+			if err != nil {
+				return err
+			}
+			headerBytes, err := hdr.Marshal()
+			if err != nil {
+				return err
+			}
+			headerCid, err := ipfs.CidV1(headerBytes)
+			if err != nil {
+				return err
+			}
+			err = os.WriteFile(headerFile, headerBytes, 0644)
 			if err != nil {
 				return err
 			}
-			payload.Body = bodyCid
-			acceptCids, err := parseCIDs(uniqueStrings(accepts.Value()))
+			fmt.Println(headerCid)
+			return nil
+		},
+	}
+}
+
+func headerBuildCmd() *cli.Command {
+
+	var keyFile string
+	var keyAlias string
+	var issuer string
+	var delegationFile string
+	var headerFile string
+	var payload header.Payload
+	var body string
+	var accepts cli.StringSlice
+	var rejects cli.StringSlice
+	var bodyFile string
+	var shapesFile string
+	var shapesMap string
+
+	return &cli.Command{
+		Name:  "build",
+		Usage: "Build a block header.",
+		Flags: append(append(append(signingKeyFlags(&keyFile, &keyAlias), []cli.Flag{
+			&cli.StringFlag{
+				Name:        "delegation-file",
+				Required:    false,
+				Usage:       "Input file for a delegation granting the key the right to issue the header",
+				Destination: &delegationFile,
+			},
+			&cli.StringFlag{
+				Name:        "issuer",
+				Required:    false,
+				Usage:       "The DID or keyring alias of the issuer, such as a did:web listing the key, if not the DID of the key",
+				Destination: &issuer,
+			},
+			&cli.StringFlag{
+				Name:        "header-file",
+				Required:    true,
+				Usage:       "Output file for the block header CBOR",
+				Destination: &headerFile,
+			},
+			&cli.StringFlag{
+				Name:        "body-file",
+				Required:    false,
+				Usage:       "Input file for the block body, to be validated against SHACL shapes",
+				Destination: &bodyFile,
+			},
+		}...), payloadFlags(&payload, &body, &accepts, &rejects)...), shapesFlags(&shapesFile, &shapesMap)...),
+		Action: func(ctx *cli.Context) error {
+			k, err := readSigningKey(keyFile, keyAlias)
 			if err != nil {
 				return err
 			}
-			payload.Accept = acceptCids
-			rejectCids, err := parseCIDs(uniqueStrings(rejects.Value()))
+			err = completePayload(&payload, body, &accepts, &rejects)
 			if err != nil {
 				return err
 			}
-			payload.Reject = rejectCids
-			header, err := payload.Sign(k)
+			catalog, err := readShapesCatalog(shapesFile, shapesMap)
 			if err != nil {
 				return err
+			} else if catalog != nil {
+				err = validateBodyFile(bodyFile, &payload, catalog)
+				if err != nil {
+					return err
+				}
 			}
-			headerBytes, err := header.Marshal()
+			var hdr *header.Header
+			if ctx.IsSet("delegation-file") && ctx.IsSet("issuer") {
+				return fmt.Errorf("--issuer may not be specified with --delegation-file")
+			} else if ctx.IsSet("issuer") {
+				issuer, err = resolveDid(issuer)
+				if err != nil {
+					return err
+				}
+				hdr, err = payload.SignAs(k, issuer)
+				if err != nil {
+					return err
+				}
+			} else if ctx.IsSet("delegation-file") {
+				delegation, err := readDelegation(delegationFile)
+				if err != nil {
+					return err
+				}
+				hdr, err = payload.SignDelegated(k, delegation)
+				if err != nil {
+					return err
+				}
+			} else {
+				hdr, err = payload.Sign(k)
+				if err != nil {
+					return err
+				}
+			}
+			headerBytes, err := hdr.Marshal()
 			if err != nil {
 				return err
 			}
@@ -132,6 +209,100 @@ func headerBuildCmd() *cli.Command {
 	}
 }
 
+func headerDelegateCmd() *cli.Command {
+
+	var keyFile string
+	var keyAlias string
+	var audience string
+	var schemaURI string
+	var mediaType string
+	var expiry string
+	var proofFile string
+	var delegationFile string
+
+	return &cli.Command{
+		Name:  "delegate",
+		Usage: "Delegate the right to issue block headers to another key.",
+		Flags: append(signingKeyFlags(&keyFile, &keyAlias), []cli.Flag{
+			&cli.StringFlag{
+				Name:        "audience",
+				Required:    true,
+				Usage:       "The DID or keyring alias of the key receiving the delegation",
+				Destination: &audience,
+			},
+			&cli.StringFlag{
+				Name:        "schema",
+				Value:       "",
+				Usage:       "Restrict the delegation to headers with this schema for the block body",
+				Destination: &schemaURI,
+			},
+			&cli.StringFlag{
+				Name:        "media-type",
+				Value:       "",
+				Usage:       "Restrict the delegation to headers with this media type for the block body",
+				Destination: &mediaType,
+			},
+			&cli.StringFlag{
+				Name:        "expiry",
+				Value:       "",
+				Usage:       "Expiry time of the delegation, in RFC 3339 format",
+				Destination: &expiry,
+			},
+			&cli.StringFlag{
+				Name:        "proof-file",
+				Required:    false,
+				Usage:       "Input file for the delegation held by the delegator, if it is not the root identity",
+				Destination: &proofFile,
+			},
+			&cli.StringFlag{
+				Name:        "delegation-file",
+				Required:    true,
+				Usage:       "Output file for the delegation CBOR",
+				Destination: &delegationFile,
+			},
+		}...),
+		Action: func(ctx *cli.Context) error {
+			k, err := readSigningKey(keyFile, keyAlias)
+			if err != nil {
+				return err
+			}
+			audience, err = resolveDid(audience)
+			if err != nil {
+				return err
+			}
+			var expiryUnix int64
+			if expiry != "" {
+				expiryTime, err := time.Parse(time.RFC3339, expiry)
+				if err != nil {
+					return err
+				}
+				expiryUnix = expiryTime.Unix()
+			}
+			var proof *header.Delegation
+			if ctx.IsSet("proof-file") {
+				proof, err = readDelegation(proofFile)
+				if err != nil {
+					return err
+				}
+			}
+			delegation, err := header.NewDelegation(k, audience, schemaURI, mediaType, expiryUnix, proof)
+			if err != nil {
+				return err
+			}
+			delegationBytes, err := delegation.Marshal()
+			if err != nil {
+				return err
+			}
+			err = os.WriteFile(delegationFile, delegationBytes, 0644)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Delegated from %s to %s\n", displayDid(delegation.Root()), displayDid(delegation.Audience))
+			return nil
+		},
+	}
+}
+
 func headerExportCmd() *cli.Command {
 
 	var headerFile string
@@ -172,6 +343,39 @@ func headerExportCmd() *cli.Command {
 	}
 }
 
+func headerPayloadCmd() *cli.Command {
+
+	var payloadFile string
+	var payload header.Payload
+	var body string
+	var accepts cli.StringSlice
+	var rejects cli.StringSlice
+
+	return &cli.Command{
+		Name:  "payload",
+		Usage: "Build the unsigned payload of a block header, for signing elsewhere.",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "payload-file",
+				Required:    true,
+				Usage:       "Output file for the header payload CBOR",
+				Destination: &payloadFile,
+			},
+		}, payloadFlags(&payload, &body, &accepts, &rejects)...),
+		Action: func(*cli.Context) error {
+			err := completePayload(&payload, body, &accepts, &rejects)
+			if err != nil {
+				return err
+			}
+			payloadBytes, err := payload.Marshal()
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(payloadFile, payloadBytes, 0644)
+		},
+	}
+}
+
 func headerVerifyCmd() *cli.Command {
 
 	var headerFile string
@@ -202,13 +406,89 @@ func headerVerifyCmd() *cli.Command {
 			} else if !okay {
 				return fmt.Errorf("signature verification failed")
 			}
-			fmt.Printf("Verified signature by %s\n", header.Issuer)
+			if header.Delegation != nil {
+				fmt.Printf("Verified signature by %s on behalf of %s\n", displayDid(header.Delegation.Audience), displayDid(header.Issuer))
+			} else {
+				fmt.Printf("Verified signature by %s\n", displayDid(header.Issuer))
+			}
 			return nil
 		},
 	}
 
 }
 
+func payloadFlags(payload *header.Payload, body *string, accepts *cli.StringSlice, rejects *cli.StringSlice) []cli.Flag {
+	return []cli.Flag{
+		&cli.Int64Flag{
+			Name:        "version",
+			Value:       1,
+			Usage:       "Header version number",
+			Destination: &payload.Version,
+		},
+		&cli.StringFlag{
+			Name:        "schema",
+			Value:       "https://w3c.github.io/json-ld-cbor/",
+			Usage:       "Schema for the block body",
+			Destination: &payload.SchemaURI,
+		},
+		&cli.StringSliceFlag{
+			Name:        "accept",
+			Usage:       "Accept a CID as a parent block",
+			Destination: accepts,
+		},
+		&cli.StringSliceFlag{
+			Name:        "reject",
+			Usage:       "Reject a CID as an ancestor block",
+			Destination: rejects,
+		},
+		&cli.StringFlag{
+			Name:        "body",
+			Required:    true,
+			Usage:       "CID for the block body",
+			Destination: body,
+		},
+		&cli.StringFlag{
+			Name:        "media-type",
+			Value:       "application/vnd.ipld.dag-cbor",
+			Usage:       "Media type for block body",
+			Destination: &payload.MediaType,
+		},
+		&cli.StringFlag{
+			Name:        "comment",
+			Value:       "",
+			Usage:       "Creator-supplied comment on the block",
+			Destination: &payload.Comment,
+		},
+	}
+}
+
+func completePayload(payload *header.Payload, body string, accepts *cli.StringSlice, rejects *cli.StringSlice) error {
+	bodyCid, err := cid.Parse(body)
+	if err != nil {
+		return err
+	}
+	payload.Body = bodyCid
+	acceptCids, err := parseCIDs(uniqueStrings(accepts.Value()))
+	if err != nil {
+		return err
+	}
+	payload.Accept = acceptCids
+	rejectCids, err := parseCIDs(uniqueStrings(rejects.Value()))
+	if err != nil {
+		return err
+	}
+	payload.Reject = rejectCids
+	return nil
+}
+
+func readDelegation(filename string) (*header.Delegation, error) {
+	delegationBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return header.UnmarshalDelegation(delegationBytes)
+}
+
 func parseCIDs(strs []string) ([]cid.Cid, error) {
 	cids := make([]cid.Cid, 0, len(strs))
 	for _, s := range strs {
@@ -8,7 +8,6 @@ import (
 
 	"github.com/functionally/nacatgunma/header"
 	"github.com/functionally/nacatgunma/ipfs"
-	"github.com/functionally/nacatgunma/key"
 	"github.com/urfave/cli/v2"
 )
 
@@ -153,6 +152,7 @@ func ipfsFetchCmd() *cli.Command {
 func ipfsStoreCmd() *cli.Command {
 
 	var keyFile string
+	var keyAlias string
 	var bodyFile string
 	var ipfsAPI string
 	var payload header.Payload
@@ -162,19 +162,13 @@ func ipfsStoreCmd() *cli.Command {
 	return &cli.Command{
 		Name:  "store",
 		Usage: "Store a block on IPFS.",
-		Flags: []cli.Flag{
+		Flags: append(signingKeyFlags(&keyFile, &keyAlias), []cli.Flag{
 			&cli.StringFlag{
 				Name:        "ipfs-api",
 				Value:       "localhost:5001",
 				Usage:       "Endpoint for the IPFS API",
 				Destination: &ipfsAPI,
 			},
-			&cli.StringFlag{
-				Name:        "key-file",
-				Required:    true,
-				Usage:       "Input file for private key",
-				Destination: &keyFile,
-			},
 			&cli.Int64Flag{
 				Name:        "version",
 				Value:       1,
@@ -209,10 +203,10 @@ func ipfsStoreCmd() *cli.Command {
 				Usage:       "Media type for body",
 				Destination: &payload.MediaType,
 			},
-		},
+		}...),
 		Action: func(*cli.Context) error {
 			sh := shell.NewShell(ipfsAPI)
-			k, err := key.ReadPrivateKey(keyFile)
+			k, err := readSigningKey(keyFile, keyAlias)
 			if err != nil {
 				return err
 			}
@@ -14,9 +14,17 @@ func KeyCmds() *cli.Command {
 		Name:  "key",
 		Usage: "Key management subcommands",
 		Subcommands: []*cli.Command{
+			keyDeriveCmd(),
 			keyDidCmd(),
+			keyDidDocumentCmd(),
+			keyExportCmd(),
 			keyGenerateCmd(),
+			keyImportCmd(),
+			keyKeyringCmds(),
 			keyResolveCmd(),
+			keySignCmd(),
+			keyThresholdCmds(),
+			keyVerifyCmd(),
 		},
 	}
 }
@@ -24,24 +32,187 @@ func KeyCmds() *cli.Command {
 func keyDidCmd() *cli.Command {
 
 	var keyFile string
+	var keyAlias string
 
 	return &cli.Command{
 		Name:  "did",
 		Usage: "Print the DID of a cryptographic key.",
+		Flags: signingKeyFlags(&keyFile, &keyAlias),
+		Action: func(*cli.Context) error {
+			k, err := readSigningKey(keyFile, keyAlias)
+			if err != nil {
+				return err
+			}
+			fmt.Println(key.Did(k))
+			return nil
+		},
+	}
+
+}
+
+func keyExportCmd() *cli.Command {
+
+	var keyFile string
+	var keyAlias string
+	var keyDid string
+	var format string
+	var private bool
+	var encrypt bool
+	var outputFile string
+
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Export a cryptographic key.",
+		Flags: append(signingKeyFlags(&keyFile, &keyAlias), []cli.Flag{
+			&cli.StringFlag{
+				Name:        "key-did",
+				Required:    false,
+				Usage:       "The DID or keyring alias for a public key to export instead of a private key",
+				Destination: &keyDid,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Value:       "jwk",
+				Usage:       "The export format, one of \"jwk\", \"pem\", or \"cardano\" for a cardano-cli signing key",
+				Destination: &format,
+			},
+			&cli.BoolFlag{
+				Name:        "private",
+				Value:       false,
+				Usage:       "Include the private key in the export",
+				Destination: &private,
+			},
+			&cli.BoolFlag{
+				Name:        "encrypt",
+				Value:       false,
+				Usage:       "Encrypt the exported PEM private key with a passphrase, read from $" + passphraseEnv + " or prompted",
+				Destination: &encrypt,
+			},
+			&cli.StringFlag{
+				Name:        "output-file",
+				Required:    true,
+				Usage:       "Output file for the exported key",
+				Destination: &outputFile,
+			},
+		}...),
+		Before: func(ctx *cli.Context) error {
+			if ctx.IsSet("key-did") && (ctx.IsSet("key-file") || ctx.IsSet("key")) {
+				return fmt.Errorf("--key-did may not be specified with --key-file or --key")
+			}
+			if encrypt && format != "pem" {
+				return fmt.Errorf("--encrypt is only supported for PEM export")
+			}
+			return nil
+		},
+		Action: func(ctx *cli.Context) error {
+			switch format {
+			case "jwk":
+				var jwkBytes []byte
+				if ctx.IsSet("key-did") {
+					if private {
+						return fmt.Errorf("a DID does not contain a private key")
+					}
+					did, err := resolveDid(keyDid)
+					if err != nil {
+						return err
+					}
+					jwkBytes, err = key.MarshalPublicJWK(did)
+					if err != nil {
+						return err
+					}
+				} else {
+					k, err := readSigningKey(keyFile, keyAlias)
+					if err != nil {
+						return err
+					}
+					jwkBytes, err = key.MarshalJWK(k, private)
+					if err != nil {
+						return err
+					}
+				}
+				mode := os.FileMode(0644)
+				if private {
+					mode = 0600
+				}
+				return os.WriteFile(outputFile, jwkBytes, mode)
+			case "pem":
+				if !private || ctx.IsSet("key-did") {
+					return fmt.Errorf("PEM export is only supported for private keys")
+				}
+				k, err := readSigningKey(keyFile, keyAlias)
+				if err != nil {
+					return err
+				}
+				return writePrivateKey(k, outputFile, encrypt)
+			case "cardano":
+				if !private || ctx.IsSet("key-did") {
+					return fmt.Errorf("Cardano export is only supported for private keys")
+				}
+				k, err := readSigningKey(keyFile, keyAlias)
+				if err != nil {
+					return err
+				}
+				envelope, err := key.MarshalTextEnvelope(k)
+				if err != nil {
+					return err
+				}
+				return os.WriteFile(outputFile, envelope, 0600)
+			default:
+				return fmt.Errorf("unsupported export format: %v", format)
+			}
+		},
+	}
+
+}
+
+func keyImportCmd() *cli.Command {
+
+	var jwkFile string
+	var keyFile string
+	var encrypt bool
+
+	return &cli.Command{
+		Name:  "import",
+		Usage: "Import a JWK, printing its DID and saving any private key.",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:        "key-file",
+				Name:        "jwk-file",
 				Required:    true,
-				Usage:       "Input file for private key",
+				Usage:       "Input JWK file",
+				Destination: &jwkFile,
+			},
+			&cli.StringFlag{
+				Name:        "key-file",
+				Required:    false,
+				Usage:       "Output file for private key",
 				Destination: &keyFile,
 			},
+			&cli.BoolFlag{
+				Name:        "encrypt",
+				Value:       false,
+				Usage:       "Encrypt the private key with a passphrase, read from $" + passphraseEnv + " or prompted",
+				Destination: &encrypt,
+			},
 		},
-		Action: func(*cli.Context) error {
-			k, err := key.ReadPrivateKey(keyFile)
+		Action: func(ctx *cli.Context) error {
+			jwkBytes, err := os.ReadFile(jwkFile)
 			if err != nil {
 				return err
 			}
-			fmt.Println(key.Did(k))
+			k, did, err := key.UnmarshalJWK(jwkBytes)
+			if err != nil {
+				return err
+			}
+			if ctx.IsSet("key-file") {
+				if k == nil {
+					return fmt.Errorf("JWK does not contain a private key")
+				}
+				err = writePrivateKey(k, keyFile, encrypt)
+				if err != nil {
+					return err
+				}
+			}
+			fmt.Println(did)
 			return nil
 		},
 	}
@@ -52,6 +223,9 @@ func keyGenerateCmd() *cli.Command {
 
 	var keyFile string
 	var keyType string
+	var encrypt bool
+	var mnemonic bool
+	var mnemonicFile string
 
 	return &cli.Command{
 		Name:  "generate",
@@ -63,27 +237,141 @@ func keyGenerateCmd() *cli.Command {
 				Usage:       "Output file for private key",
 				Destination: &keyFile,
 			},
+			&cli.StringFlag{
+				Name:        "key-type",
+				Value:       "Ed25519",
+				Usage:       "The key type, one of \"Ed25519\", \"BLS12-381\", \"secp256k1\", or \"P-256\"",
+				Destination: &keyType,
+			},
+			&cli.BoolFlag{
+				Name:        "encrypt",
+				Value:       false,
+				Usage:       "Encrypt the private key with a passphrase, read from $" + passphraseEnv + " or prompted",
+				Destination: &encrypt,
+			},
+			&cli.BoolFlag{
+				Name:        "mnemonic",
+				Value:       false,
+				Usage:       "Derive the key from a new BIP-39 mnemonic, which is written to the mnemonic file",
+				Destination: &mnemonic,
+			},
+			&cli.StringFlag{
+				Name:        "mnemonic-file",
+				Required:    false,
+				Usage:       "Output file for the mnemonic",
+				Destination: &mnemonicFile,
+			},
+		},
+		Before: func(ctx *cli.Context) error {
+			if mnemonic != ctx.IsSet("mnemonic-file") {
+				return fmt.Errorf("--mnemonic and --mnemonic-file must be specified together")
+			}
+			return nil
+		},
+		Action: func(*cli.Context) error {
+			kt, err := parseKeyType(keyType)
+			if err != nil {
+				return err
+			}
+			var k key.Key
+			if mnemonic {
+				words, err := key.GenerateMnemonic()
+				if err != nil {
+					return err
+				}
+				seed, err := key.SeedFromMnemonic(words, "")
+				if err != nil {
+					return err
+				}
+				k, err = key.DeriveKey(kt, seed, key.DefaultPath(kt))
+				if err != nil {
+					return err
+				}
+				err = os.WriteFile(mnemonicFile, []byte(words+"\n"), 0600)
+				if err != nil {
+					return err
+				}
+			} else {
+				k, err = key.GenerateKey(kt)
+				if err != nil {
+					return err
+				}
+			}
+			err = writePrivateKey(k, keyFile, encrypt)
+			if err != nil {
+				return err
+			}
+			fmt.Println(key.Did(k))
+			return nil
+		},
+	}
+
+}
+
+func keyDeriveCmd() *cli.Command {
+
+	var mnemonicFile string
+	var path string
+	var keyType string
+	var keyFile string
+	var encrypt bool
+
+	return &cli.Command{
+		Name:  "derive",
+		Usage: "Derive a cryptographic key from a BIP-39 mnemonic.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "mnemonic-file",
+				Required:    true,
+				Usage:       "Input file for the mnemonic",
+				Destination: &mnemonicFile,
+			},
+			&cli.StringFlag{
+				Name:        "path",
+				Value:       "",
+				Usage:       "The derivation path, with hardened indices marked by \"'\", defaulting to that of \"key generate --mnemonic\"",
+				Destination: &path,
+			},
 			&cli.StringFlag{
 				Name:        "key-type",
 				Value:       "Ed25519",
 				Usage:       "The key type, either \"Ed25519\" or \"BLS12-381\"",
 				Destination: &keyType,
 			},
+			&cli.StringFlag{
+				Name:        "key-file",
+				Required:    true,
+				Usage:       "Output file for private key",
+				Destination: &keyFile,
+			},
+			&cli.BoolFlag{
+				Name:        "encrypt",
+				Value:       false,
+				Usage:       "Encrypt the private key with a passphrase, read from $" + passphraseEnv + " or prompted",
+				Destination: &encrypt,
+			},
 		},
 		Action: func(*cli.Context) error {
-			allowed := map[string]key.KeyType{
-				"Ed25519":   key.Ed25519,
-				"BLS12-381": key.Bls12381,
+			kt, err := parseKeyType(keyType)
+			if err != nil {
+				return err
+			}
+			if path == "" {
+				path = key.DefaultPath(kt)
 			}
-			kt, okay := allowed[keyType]
-			if !okay {
-				return fmt.Errorf("unsupported key type: %v", keyType)
+			words, err := os.ReadFile(mnemonicFile)
+			if err != nil {
+				return err
 			}
-			k, err := key.GenerateKey(kt)
+			seed, err := key.SeedFromMnemonic(string(words), "")
 			if err != nil {
 				return err
 			}
-			err = key.WritePrivateKey(k, keyFile)
+			k, err := key.DeriveKey(kt, seed, path)
+			if err != nil {
+				return err
+			}
+			err = writePrivateKey(k, keyFile, encrypt)
 			if err != nil {
 				return err
 			}
@@ -101,12 +389,12 @@ func keyResolveCmd() *cli.Command {
 
 	return &cli.Command{
 		Name:  "resolve",
-		Usage: "Resolve an Ed25519 key.",
+		Usage: "Resolve a DID, such as a did:key, did:web, or did:peer.",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:        "key-did",
 				Required:    true,
-				Usage:       "The DID for the public key",
+				Usage:       "The DID or keyring alias for the public key",
 				Destination: &keyDid,
 			},
 			&cli.StringFlag{
@@ -117,7 +405,11 @@ func keyResolveCmd() *cli.Command {
 			},
 		},
 		Action: func(*cli.Context) error {
-			resolution, err := key.ResolveDid(keyDid)
+			did, err := resolveDid(keyDid)
+			if err != nil {
+				return err
+			}
+			resolution, err := key.ResolveDid(did)
 			if err != nil {
 				return err
 			}
@@ -134,3 +426,171 @@ func keyResolveCmd() *cli.Command {
 	}
 
 }
+
+func keySignCmd() *cli.Command {
+
+	var keyFile string
+	var keyAlias string
+	var inputFile string
+	var context string
+	var format string
+	var signatureFile string
+
+	return &cli.Command{
+		Name:  "sign",
+		Usage: "Make a detached signature of a file.",
+		Flags: append(signingKeyFlags(&keyFile, &keyAlias), []cli.Flag{
+			&cli.StringFlag{
+				Name:        "input-file",
+				Aliases:     []string{"input"},
+				Required:    true,
+				Usage:       "Input file to be signed",
+				Destination: &inputFile,
+			},
+			&cli.StringFlag{
+				Name:        "context",
+				Value:       "",
+				Usage:       "The signature context, which must match when verifying",
+				Destination: &context,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Value:       "jws",
+				Usage:       "The signature format, either \"jws\" for detached JWS compact serialization or \"raw\" for signature bytes",
+				Destination: &format,
+			},
+			&cli.StringFlag{
+				Name:        "signature-file",
+				Aliases:     []string{"signature"},
+				Value:       "/dev/stdout",
+				Usage:       "Output file for the signature",
+				Destination: &signatureFile,
+			},
+		}...),
+		Action: func(*cli.Context) error {
+			k, err := readSigningKey(keyFile, keyAlias)
+			if err != nil {
+				return err
+			}
+			message, err := os.ReadFile(inputFile)
+			if err != nil {
+				return err
+			}
+			var signature []byte
+			switch format {
+			case "jws":
+				jws, err := key.SignDetachedJWS(k, message, context)
+				if err != nil {
+					return err
+				}
+				signature = []byte(jws + "\n")
+			case "raw":
+				signature, err = k.Sign(message, context)
+				if err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unsupported signature format: %v", format)
+			}
+			return os.WriteFile(signatureFile, signature, 0644)
+		},
+	}
+
+}
+
+func keyVerifyCmd() *cli.Command {
+
+	var keyDid string
+	var signatureFile string
+	var inputFile string
+	var context string
+	var format string
+
+	return &cli.Command{
+		Name:  "verify",
+		Usage: "Verify a detached signature of a file.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "key-did",
+				Aliases:     []string{"did"},
+				Required:    false,
+				Usage:       "The DID or keyring alias of the signer, which is required for raw signatures and otherwise taken from the JWS",
+				Destination: &keyDid,
+			},
+			&cli.StringFlag{
+				Name:        "signature-file",
+				Aliases:     []string{"signature"},
+				Required:    true,
+				Usage:       "Input file for the signature",
+				Destination: &signatureFile,
+			},
+			&cli.StringFlag{
+				Name:        "input-file",
+				Aliases:     []string{"input"},
+				Required:    true,
+				Usage:       "Input file that was signed",
+				Destination: &inputFile,
+			},
+			&cli.StringFlag{
+				Name:        "context",
+				Value:       "",
+				Usage:       "The signature context for raw signatures, which JWS signatures carry in their header",
+				Destination: &context,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Value:       "jws",
+				Usage:       "The signature format, either \"jws\" or \"raw\"",
+				Destination: &format,
+			},
+		},
+		Action: func(*cli.Context) error {
+			signature, err := os.ReadFile(signatureFile)
+			if err != nil {
+				return err
+			}
+			message, err := os.ReadFile(inputFile)
+			if err != nil {
+				return err
+			}
+			signer, err := resolveDid(keyDid)
+			if err != nil {
+				return err
+			}
+			switch format {
+			case "jws":
+				signer, err = key.VerifyDetachedJWS(signer, string(signature), message)
+				if err != nil {
+					return err
+				}
+			case "raw":
+				if signer == "" {
+					return fmt.Errorf("--key-did is required for raw signatures")
+				}
+				err = key.Verify(signer, signature, message, context)
+				if err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unsupported signature format: %v", format)
+			}
+			fmt.Printf("Verified signature by %s\n", displayDid(signer))
+			return nil
+		},
+	}
+
+}
+
+func parseKeyType(keyType string) (key.KeyType, error) {
+	allowed := map[string]key.KeyType{
+		"Ed25519":   key.Ed25519,
+		"BLS12-381": key.Bls12381,
+		"secp256k1": key.Secp256k1,
+		"P-256":     key.P256,
+	}
+	kt, okay := allowed[keyType]
+	if !okay {
+		return key.UnknownKeyType, fmt.Errorf("unsupported key type: %v", keyType)
+	}
+	return kt, nil
+}
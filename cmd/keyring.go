@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/functionally/nacatgunma/key"
+)
+
+// The environment variable overriding the location of the keyring directory.
+const keyringEnv = "NACATGUNMA_KEYRING"
+
+func openKeyring() (*key.Keyring, error) {
+	dir, okay := os.LookupEnv(keyringEnv)
+	if !okay {
+		config, err := os.UserConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(config, "nacatgunma", "keyring")
+	}
+	return key.OpenKeyring(dir)
+}
+
+// signingKeyFlags lets a command take its private key from either a file or the keyring,
+// falling back to the default key in the keyring if neither is specified.
+func signingKeyFlags(keyFile *string, keyAlias *string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "key-file",
+			Required:    false,
+			Usage:       "Input file for private key",
+			Destination: keyFile,
+		},
+		&cli.StringFlag{
+			Name:        "key",
+			Required:    false,
+			Usage:       "Alias of the private key in the keyring, instead of --key-file",
+			Destination: keyAlias,
+		},
+	}
+}
+
+func readSigningKey(keyFile string, keyAlias string) (key.Key, error) {
+	if keyFile != "" && keyAlias != "" {
+		return nil, fmt.Errorf("at most one of --key-file or --key may be specified")
+	}
+	if keyFile == "" {
+		keyring, err := openKeyring()
+		if err != nil {
+			return nil, err
+		}
+		keyFile, err = keyring.KeyFile(keyAlias)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return readPrivateKey(keyFile)
+}
+
+// resolveDid looks up an alias in the keyring, passing DIDs through unchanged.
+func resolveDid(aliasOrDid string) (string, error) {
+	if aliasOrDid == "" || strings.HasPrefix(aliasOrDid, "did:") {
+		return aliasOrDid, nil
+	}
+	keyring, err := openKeyring()
+	if err != nil {
+		return "", err
+	}
+	return keyring.ResolveAlias(aliasOrDid)
+}
+
+// displayDid shows the alias of a DID in the keyring, if there is one.
+func displayDid(did string) string {
+	keyring, err := openKeyring()
+	if err != nil {
+		return did
+	}
+	return keyring.Display(did)
+}
+
+func keyKeyringCmds() *cli.Command {
+	return &cli.Command{
+		Name:  "keyring",
+		Usage: "Keyring subcommands, with the keyring directory set by $" + keyringEnv,
+		Subcommands: []*cli.Command{
+			keyKeyringAddCmd(),
+			keyKeyringDefaultCmd(),
+			keyKeyringListCmd(),
+			keyKeyringRemoveCmd(),
+		},
+	}
+}
+
+func keyKeyringAddCmd() *cli.Command {
+
+	var alias string
+	var keyFile string
+	var keyDid string
+
+	return &cli.Command{
+		Name:  "add",
+		Usage: "Add a private key file or a DID to the keyring.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "alias",
+				Required:    true,
+				Usage:       "The alias for the key",
+				Destination: &alias,
+			},
+			&cli.StringFlag{
+				Name:        "key-file",
+				Required:    false,
+				Usage:       "Input file for private key",
+				Destination: &keyFile,
+			},
+			&cli.StringFlag{
+				Name:        "key-did",
+				Required:    false,
+				Usage:       "The DID for a public key, instead of a private key file",
+				Destination: &keyDid,
+			},
+		},
+		Before: func(ctx *cli.Context) error {
+			if ctx.IsSet("key-file") == ctx.IsSet("key-did") {
+				return fmt.Errorf("exactly one of --key-file or --key-did must be specified")
+			}
+			return nil
+		},
+		Action: func(*cli.Context) error {
+			keyring, err := openKeyring()
+			if err != nil {
+				return err
+			}
+			if keyFile != "" {
+				k, err := readPrivateKey(keyFile)
+				if err != nil {
+					return err
+				}
+				keyDid = key.Did(k)
+				keyFile, err = filepath.Abs(keyFile)
+				if err != nil {
+					return err
+				}
+			}
+			err = keyring.Add(alias, keyDid, keyFile)
+			if err != nil {
+				return err
+			}
+			return keyring.Save()
+		},
+	}
+
+}
+
+func keyKeyringDefaultCmd() *cli.Command {
+
+	var alias string
+
+	return &cli.Command{
+		Name:  "default",
+		Usage: "Set the default private key in the keyring.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "alias",
+				Required:    true,
+				Usage:       "The alias for the key",
+				Destination: &alias,
+			},
+		},
+		Action: func(*cli.Context) error {
+			keyring, err := openKeyring()
+			if err != nil {
+				return err
+			}
+			err = keyring.SetDefault(alias)
+			if err != nil {
+				return err
+			}
+			return keyring.Save()
+		},
+	}
+
+}
+
+func keyKeyringListCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List the keys in the keyring, marking the default with \"*\".",
+		Action: func(*cli.Context) error {
+			keyring, err := openKeyring()
+			if err != nil {
+				return err
+			}
+			for _, alias := range keyring.Aliases() {
+				entry := keyring.Entries[alias]
+				marker := " "
+				if alias == keyring.Default {
+					marker = "*"
+				}
+				fmt.Printf("%s %s\t%s\t%s\n", marker, alias, entry.Did, entry.File)
+			}
+			return nil
+		},
+	}
+}
+
+func keyKeyringRemoveCmd() *cli.Command {
+
+	var alias string
+
+	return &cli.Command{
+		Name:  "remove",
+		Usage: "Remove a key from the keyring, leaving any private key file in place.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "alias",
+				Required:    true,
+				Usage:       "The alias for the key",
+				Destination: &alias,
+			},
+		},
+		Action: func(*cli.Context) error {
+			keyring, err := openKeyring()
+			if err != nil {
+				return err
+			}
+			err = keyring.Remove(alias)
+			if err != nil {
+				return err
+			}
+			return keyring.Save()
+		},
+	}
+}
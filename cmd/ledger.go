@@ -3,11 +3,16 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
 
+	"github.com/ipfs/go-cid"
 	"github.com/urfave/cli/v2"
 
 	"github.com/functionally/nacatgunma/ledger"
+	"github.com/functionally/nacatgunma/rdf"
 )
 
 func LedgerCmds() *cli.Command {
@@ -15,8 +20,347 @@ func LedgerCmds() *cli.Command {
 		Name:  "ledger",
 		Usage: "Body management subcommands",
 		Subcommands: []*cli.Command{
+			ledgerConflictsCmd(),
+			ledgerContainsCmd(),
+			ledgerDiffCmd(),
+			ledgerExplainCmd(),
 			ledgerExportCmd(),
+			ledgerGraphCmd(),
+			ledgerMergeBaseCmd(),
+			ledgerPathCmd(),
 			ledgerPruneCmd(),
+			ledgerResolveCmd(),
+			ledgerStateCmd(),
+			ledgerValidateCmd(),
+		},
+	}
+}
+
+func ledgerConflictsCmd() *cli.Command {
+
+	var tipCid string
+	var headerDir string
+	var bodyDir string
+	var properties cli.StringSlice
+	var declared bool
+	var format string
+
+	return &cli.Command{
+		Name:  "conflicts",
+		Usage: "Report conflicting assertions of functional properties in the RDF state graph.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "tip-cid",
+				Required:    true,
+				Usage:       "The CID for the block header of the tip of the chain",
+				Destination: &tipCid,
+			},
+			&cli.StringFlag{
+				Name:        "header-dir",
+				Required:    true,
+				Usage:       "Input folder for the block headers",
+				Destination: &headerDir,
+			},
+			&cli.StringFlag{
+				Name:        "body-dir",
+				Required:    true,
+				Usage:       "Input folder for the block bodies",
+				Destination: &bodyDir,
+			},
+			&cli.StringSliceFlag{
+				Name:        "property",
+				Usage:       "The IRI of a property that should have a single value per subject",
+				Destination: &properties,
+			},
+			&cli.BoolFlag{
+				Name:        "declared",
+				Value:       true,
+				Usage:       "Also check properties declared as owl:FunctionalProperty in the state",
+				Destination: &declared,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Value:       "text",
+				Usage:       "Output format: text or json",
+				Destination: &format,
+			},
+		},
+		Action: func(*cli.Context) error {
+			ledger, err := ledger.ReadLedger(tipCid, headerDir)
+			if err != nil {
+				return err
+			}
+			state, err := ledger.AssembleState(bodyDir)
+			if err != nil {
+				return err
+			}
+			functional := properties.Value()
+			if declared {
+				functional = append(functional, state.FunctionalProperties()...)
+			}
+			conflicts := state.Conflicts(uniqueStrings(functional))
+			switch format {
+			case "json":
+				json, err := json.MarshalIndent(conflicts, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal conflicts: %w", err)
+				}
+				fmt.Println(string(json))
+			case "text":
+				for _, conflict := range conflicts {
+					fmt.Printf("%v %v\n", conflict.Subject, conflict.Property)
+					for _, assertion := range conflict.Assertions {
+						fmt.Printf("  %v asserted by %v in block %v\n", assertion.Object, displayDid(assertion.Issuer), assertion.Block)
+						for _, block := range assertion.Supersedes {
+							fmt.Printf("    supersedes %v\n", block)
+						}
+						for _, block := range assertion.Concurrent {
+							fmt.Printf("    concurrent with %v\n", block)
+						}
+						if assertion.WithinBlock {
+							fmt.Printf("    conflicts within its block\n")
+						}
+					}
+				}
+			default:
+				return fmt.Errorf("unsupported conflict format: %v", format)
+			}
+			return nil
+		},
+	}
+}
+
+func ledgerContainsCmd() *cli.Command {
+
+	var tipCid string
+	var headerDir string
+	var blockCid string
+
+	return &cli.Command{
+		Name:  "contains",
+		Usage: "Check whether a block is an ancestor of the tip, and whether it is visible from there.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "tip-cid",
+				Required:    true,
+				Usage:       "The CID for the block header of the tip of the chain",
+				Destination: &tipCid,
+			},
+			&cli.StringFlag{
+				Name:        "header-dir",
+				Required:    true,
+				Usage:       "Input folder for the block headers",
+				Destination: &headerDir,
+			},
+			&cli.StringFlag{
+				Name:        "cid",
+				Required:    true,
+				Usage:       "The CID for the block header to look for",
+				Destination: &blockCid,
+			},
+		},
+		Action: func(*cli.Context) error {
+			ledger, err := ledger.ReadLedger(tipCid, headerDir)
+			if err != nil {
+				return err
+			}
+			c, err := cid.Parse(blockCid)
+			if err != nil {
+				return err
+			}
+			// Headers also holds the blocks reached only through reject links, which are not ancestors.
+			ancestor := false
+			if _, present := ledger.Headers[c]; present {
+				ancestor, err = ledger.MakeHeaderTable().IsAncestor(c, ledger.Tip)
+				if err != nil {
+					return err
+				}
+			}
+			if !ancestor {
+				fmt.Printf("%v is not an ancestor of %v\n", c, ledger.Tip)
+			} else if ledger.Reachable()[c] {
+				fmt.Printf("%v is an ancestor of %v and is visible\n", c, ledger.Tip)
+			} else {
+				fmt.Printf("%v is an ancestor of %v but is rejected\n", c, ledger.Tip)
+			}
+			return nil
+		},
+	}
+}
+
+func ledgerDiffCmd() *cli.Command {
+
+	var fromTip string
+	var toTip string
+	var headerDir string
+	var format string
+
+	return &cli.Command{
+		Name:  "diff",
+		Usage: "Compare the visible blocks of two tips.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "from-tip",
+				Required:    true,
+				Usage:       "The CID for the block header of the original tip",
+				Destination: &fromTip,
+			},
+			&cli.StringFlag{
+				Name:        "to-tip",
+				Required:    true,
+				Usage:       "The CID for the block header of the proposed tip",
+				Destination: &toTip,
+			},
+			&cli.StringFlag{
+				Name:        "header-dir",
+				Required:    true,
+				Usage:       "Input folder for the block headers",
+				Destination: &headerDir,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Value:       "text",
+				Usage:       "Output format: text or json",
+				Destination: &format,
+			},
+		},
+		Action: func(*cli.Context) error {
+			from, err := ledger.ReadLedger(fromTip, headerDir)
+			if err != nil {
+				return err
+			}
+			to, err := ledger.ReadLedger(toTip, headerDir)
+			if err != nil {
+				return err
+			}
+			diff := ledger.DiffLedgers(from, to)
+			switch format {
+			case "json":
+				json, err := json.MarshalIndent(diff, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal diff: %w", err)
+				}
+				fmt.Println(string(json))
+			case "text":
+				for _, c := range diff.Visible {
+					fmt.Printf("visible     %v\n", c)
+				}
+				for _, c := range diff.Reaccepted {
+					fmt.Printf("reaccepted  %v\n", c)
+				}
+				for _, removal := range diff.Rejected {
+					if len(removal.RejectedBy) == 0 {
+						fmt.Printf("unreachable %v\n", removal.Block)
+						continue
+					}
+					var rejectors []string
+					for _, c := range removal.RejectedBy {
+						rejectors = append(rejectors, c.String())
+					}
+					fmt.Printf("rejected    %v by %v\n", removal.Block, strings.Join(rejectors, ", "))
+				}
+			default:
+				return fmt.Errorf("unsupported diff format: %v", format)
+			}
+			return nil
+		},
+	}
+}
+
+func ledgerExplainCmd() *cli.Command {
+
+	var tipCid string
+	var headerDir string
+	var blockCids cli.StringSlice
+	var format string
+
+	return &cli.Command{
+		Name:  "explain",
+		Usage: "Explain which blocks reject the invisible blocks of the ledger.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "tip-cid",
+				Required:    true,
+				Usage:       "The CID for the block header of the tip of the chain",
+				Destination: &tipCid,
+			},
+			&cli.StringFlag{
+				Name:        "header-dir",
+				Required:    true,
+				Usage:       "Input folder for the block headers",
+				Destination: &headerDir,
+			},
+			&cli.StringSliceFlag{
+				Name:        "cid",
+				Usage:       "The CID for a block header to explain, instead of every invisible block",
+				Destination: &blockCids,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Value:       "text",
+				Usage:       "Output format: text or json",
+				Destination: &format,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			ledger, err := ledger.ReadLedger(tipCid, headerDir)
+			if err != nil {
+				return err
+			}
+			explanations := ledger.Explain()
+			var cids []cid.Cid
+			if ctx.IsSet("cid") {
+				cids, err = parseCIDs(uniqueStrings(blockCids.Value()))
+				if err != nil {
+					return err
+				}
+				for _, c := range cids {
+					if _, present := ledger.Headers[c]; !present {
+						return fmt.Errorf("block %v is not in the ledger", c)
+					}
+				}
+			} else {
+				for c := range explanations {
+					cids = append(cids, c)
+				}
+				sort.Slice(cids, func(i, j int) bool {
+					return cids[i].String() < cids[j].String()
+				})
+			}
+			switch format {
+			case "json":
+				selected := make(map[cid.Cid]interface{})
+				for _, c := range cids {
+					selected[c] = explanations[c]
+				}
+				json, err := json.MarshalIndent(selected, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal explanation: %w", err)
+				}
+				fmt.Println(string(json))
+			case "text":
+				for _, c := range cids {
+					rejections, rejected := explanations[c]
+					if !rejected {
+						fmt.Printf("%v is visible\n", c)
+						continue
+					}
+					for _, rejection := range rejections {
+						var path []string
+						for _, p := range rejection.Path {
+							path = append(path, p.String())
+						}
+						if rejection.Rejected == c {
+							fmt.Printf("%v is rejected by %v along %v\n", c, rejection.Rejector, strings.Join(path, " -> "))
+						} else {
+							fmt.Printf("%v is hidden by %v rejecting %v along %v\n", c, rejection.Rejector, rejection.Rejected, strings.Join(path, " -> "))
+						}
+					}
+				}
+			default:
+				return fmt.Errorf("unsupported explanation format: %v", format)
+			}
+			return nil
 		},
 	}
 }
@@ -62,15 +406,14 @@ func ledgerExportCmd() *cli.Command {
 			if err != nil {
 				return err
 			}
-			prunable := ledger.Prunable()
 			if ctx.IsSet("turtle-file") {
-				err = ledger.WriteLedgerTurtle(turtleFile, prunable)
+				err = ledger.WriteLedgerTurtle(turtleFile, ledger.Rejectors())
 				if err != nil {
 					return err
 				}
 			}
 			if ctx.IsSet("json-file") {
-				json, err := json.MarshalIndent(ledger, "", "  ")
+				json, err := json.MarshalIndent(ledger.Export(), "", "  ")
 				if err != nil {
 					return fmt.Errorf("failed to marshal ledger: %w", err)
 				}
@@ -84,6 +427,147 @@ func ledgerExportCmd() *cli.Command {
 	}
 }
 
+func ledgerGraphCmd() *cli.Command {
+
+	var tipCid string
+	var headerDir string
+	var format string
+	var outputFile string
+
+	return &cli.Command{
+		Name:  "graph",
+		Usage: "Render the ledger DAG with its accept and reject edges.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "tip-cid",
+				Required:    true,
+				Usage:       "The CID for the block header of the tip of the chain",
+				Destination: &tipCid,
+			},
+			&cli.StringFlag{
+				Name:        "header-dir",
+				Required:    true,
+				Usage:       "Input folder for the block headers",
+				Destination: &headerDir,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Value:       "dot",
+				Usage:       "Output format: dot, mermaid, or graphml",
+				Destination: &format,
+			},
+			&cli.StringFlag{
+				Name:        "output-file",
+				Usage:       "Output file for the rendering, instead of standard output",
+				Destination: &outputFile,
+			},
+		},
+		Action: func(*cli.Context) error {
+			ledger, err := ledger.ReadLedger(tipCid, headerDir)
+			if err != nil {
+				return err
+			}
+			return withOutput(outputFile, func(w io.Writer) error {
+				return ledger.WriteGraph(w, format, displayDid)
+			})
+		},
+	}
+}
+
+func ledgerMergeBaseCmd() *cli.Command {
+
+	var tipCids cli.StringSlice
+	var headerDir string
+
+	return &cli.Command{
+		Name:  "merge-base",
+		Usage: "Find the lowest common ancestors of several tips.",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:        "tip-cid",
+				Required:    true,
+				Usage:       "The CID for the block header of a tip",
+				Destination: &tipCids,
+			},
+			&cli.StringFlag{
+				Name:        "header-dir",
+				Required:    true,
+				Usage:       "Input folder for the block headers",
+				Destination: &headerDir,
+			},
+		},
+		Action: func(*cli.Context) error {
+			tips := uniqueStrings(tipCids.Value())
+			ledger, err := ledger.ReadLedgers(tips, headerDir)
+			if err != nil {
+				return err
+			}
+			cids, err := parseCIDs(tips)
+			if err != nil {
+				return err
+			}
+			lowest, err := ledger.MakeHeaderTable().CommonAncestors(cids)
+			if err != nil {
+				return err
+			}
+			for _, c := range lowest {
+				fmt.Println(c)
+			}
+			return nil
+		},
+	}
+}
+
+func ledgerPathCmd() *cli.Command {
+
+	var fromCid string
+	var toCid string
+	var headerDir string
+
+	return &cli.Command{
+		Name:  "path",
+		Usage: "Find a shortest accept-path between two blocks.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "from-cid",
+				Required:    true,
+				Usage:       "The CID for the block header at one end of the path",
+				Destination: &fromCid,
+			},
+			&cli.StringFlag{
+				Name:        "to-cid",
+				Required:    true,
+				Usage:       "The CID for the block header at the other end of the path",
+				Destination: &toCid,
+			},
+			&cli.StringFlag{
+				Name:        "header-dir",
+				Required:    true,
+				Usage:       "Input folder for the block headers",
+				Destination: &headerDir,
+			},
+		},
+		Action: func(*cli.Context) error {
+			ledger, err := ledger.ReadLedgers([]string{fromCid, toCid}, headerDir)
+			if err != nil {
+				return err
+			}
+			cids, err := parseCIDs([]string{fromCid, toCid})
+			if err != nil {
+				return err
+			}
+			path, err := ledger.MakeHeaderTable().ShortestPath(cids[0], cids[1])
+			if err != nil {
+				return err
+			}
+			for _, c := range path {
+				fmt.Println(c)
+			}
+			return nil
+		},
+	}
+}
+
 func ledgerPruneCmd() *cli.Command {
 
 	var tipCid string
@@ -177,3 +661,308 @@ func ledgerPruneCmd() *cli.Command {
 	}
 
 }
+
+func ledgerResolveCmd() *cli.Command {
+
+	var tipCid string
+	var headerDir string
+	var bodyDir string
+	var properties cli.StringSlice
+	var declared bool
+	var policy string
+	var trustFile string
+	var reification string
+	var format string
+	var outputFile string
+	var reportFile string
+
+	return &cli.Command{
+		Name:  "resolve",
+		Usage: "Resolve conflicting assertions in the RDF state graph under a trust policy.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "tip-cid",
+				Required:    true,
+				Usage:       "The CID for the block header of the tip of the chain",
+				Destination: &tipCid,
+			},
+			&cli.StringFlag{
+				Name:        "header-dir",
+				Required:    true,
+				Usage:       "Input folder for the block headers",
+				Destination: &headerDir,
+			},
+			&cli.StringFlag{
+				Name:        "body-dir",
+				Required:    true,
+				Usage:       "Input folder for the block bodies",
+				Destination: &bodyDir,
+			},
+			&cli.StringSliceFlag{
+				Name:        "property",
+				Usage:       "The IRI of a property that should have a single value per subject",
+				Destination: &properties,
+			},
+			&cli.BoolFlag{
+				Name:        "declared",
+				Value:       true,
+				Usage:       "Also resolve properties declared as owl:FunctionalProperty in the state",
+				Destination: &declared,
+			},
+			&cli.StringFlag{
+				Name:        "policy",
+				Value:       rdf.LastWriterPolicy,
+				Usage:       "Resolution policy: last-writer, highest-trust, or majority",
+				Destination: &policy,
+			},
+			&cli.StringFlag{
+				Name:        "trust-file",
+				Usage:       "Input JSON file mapping issuer DIDs or keyring aliases to trust between 0 and 1",
+				Destination: &trustFile,
+			},
+			&cli.StringFlag{
+				Name:        "reification",
+				Value:       rdf.NamedGraphReification,
+				Usage:       "Reification of the block assertions: named-graph, rdf-star, or prov",
+				Destination: &reification,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Value:       "nquads",
+				Usage:       "Output format: nquads, trig for named graphs, or turtle for RDF-star and PROV-O",
+				Destination: &format,
+			},
+			&cli.StringFlag{
+				Name:        "output-file",
+				Usage:       "Output file for the resolved graph, instead of standard output",
+				Destination: &outputFile,
+			},
+			&cli.StringFlag{
+				Name:        "report-file",
+				Usage:       "Output JSON file for the report of which assertion won each conflict and why",
+				Destination: &reportFile,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			trust := rdf.Trust{}
+			if ctx.IsSet("trust-file") {
+				var err error
+				trust, err = readTrust(trustFile)
+				if err != nil {
+					return err
+				}
+			} else if policy != rdf.LastWriterPolicy {
+				return fmt.Errorf("the %v policy requires a trust file", policy)
+			}
+			ledger, err := ledger.ReadLedger(tipCid, headerDir)
+			if err != nil {
+				return err
+			}
+			state, err := ledger.AssembleState(bodyDir)
+			if err != nil {
+				return err
+			}
+			functional := properties.Value()
+			if declared {
+				functional = append(functional, state.FunctionalProperties()...)
+			}
+			resolved, resolutions, err := state.Resolve(uniqueStrings(functional), policy, trust)
+			if err != nil {
+				return err
+			}
+			if ctx.IsSet("report-file") {
+				json, err := json.MarshalIndent(resolutions, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal resolutions: %w", err)
+				}
+				err = os.WriteFile(reportFile, json, 0644)
+				if err != nil {
+					return err
+				}
+			}
+			return withOutput(outputFile, func(w io.Writer) error {
+				return resolved.Write(w, reification, format)
+			})
+		},
+	}
+}
+
+func ledgerStateCmd() *cli.Command {
+
+	var tipCid string
+	var headerDir string
+	var bodyDir string
+	var reification string
+	var format string
+	var outputFile string
+
+	return &cli.Command{
+		Name:  "state",
+		Usage: "Assemble the RDF state graph from the JSON-LD bodies of the visible blocks.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "tip-cid",
+				Required:    true,
+				Usage:       "The CID for the block header of the tip of the chain",
+				Destination: &tipCid,
+			},
+			&cli.StringFlag{
+				Name:        "header-dir",
+				Required:    true,
+				Usage:       "Input folder for the block headers",
+				Destination: &headerDir,
+			},
+			&cli.StringFlag{
+				Name:        "body-dir",
+				Required:    true,
+				Usage:       "Input folder for the block bodies",
+				Destination: &bodyDir,
+			},
+			&cli.StringFlag{
+				Name:        "reification",
+				Value:       rdf.NamedGraphReification,
+				Usage:       "Reification of the block assertions: named-graph, rdf-star, or prov",
+				Destination: &reification,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Value:       "nquads",
+				Usage:       "Output format: nquads, trig for named graphs, or turtle for RDF-star and PROV-O",
+				Destination: &format,
+			},
+			&cli.StringFlag{
+				Name:        "output-file",
+				Usage:       "Output file for the state graph, instead of standard output",
+				Destination: &outputFile,
+			},
+		},
+		Action: func(*cli.Context) error {
+			ledger, err := ledger.ReadLedger(tipCid, headerDir)
+			if err != nil {
+				return err
+			}
+			state, err := ledger.AssembleState(bodyDir)
+			if err != nil {
+				return err
+			}
+			return withOutput(outputFile, func(w io.Writer) error {
+				return state.Write(w, reification, format)
+			})
+		},
+	}
+}
+
+func ledgerValidateCmd() *cli.Command {
+
+	var tipCid string
+	var headerDir string
+	var bodyDir string
+	var shapesFile string
+	var shapesMap string
+
+	return &cli.Command{
+		Name:  "validate",
+		Usage: "Verify the signatures and delegations of the block headers in the ledger, and optionally validate the JSON-LD bodies of the visible blocks against SHACL shapes.",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "tip-cid",
+				Required:    true,
+				Usage:       "The CID for the block header of the tip of the chain",
+				Destination: &tipCid,
+			},
+			&cli.StringFlag{
+				Name:        "header-dir",
+				Required:    true,
+				Usage:       "Input folder for the block headers",
+				Destination: &headerDir,
+			},
+			&cli.StringFlag{
+				Name:        "body-dir",
+				Required:    false,
+				Usage:       "Input folder for the block bodies, to be validated against SHACL shapes",
+				Destination: &bodyDir,
+			},
+		}, shapesFlags(&shapesFile, &shapesMap)...),
+		Action: func(*cli.Context) error {
+			catalog, err := readShapesCatalog(shapesFile, shapesMap)
+			if err != nil {
+				return err
+			} else if catalog != nil && bodyDir == "" {
+				return fmt.Errorf("--body-dir must be specified to validate the bodies")
+			}
+			ledger, err := ledger.ReadLedger(tipCid, headerDir)
+			if err != nil {
+				return err
+			}
+			err = ledger.Validate()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Verified %d block headers\n", len(ledger.Headers))
+			if catalog == nil {
+				return nil
+			}
+			reports, err := ledger.ValidateBodies(bodyDir, catalog)
+			if err != nil {
+				return err
+			}
+			var failed []cid.Cid
+			for hdrCid, report := range reports {
+				if !report.Conforms {
+					failed = append(failed, hdrCid)
+				}
+			}
+			sort.Slice(failed, func(i, j int) bool {
+				return failed[i].String() < failed[j].String()
+			})
+			for _, hdrCid := range failed {
+				fmt.Fprintf(os.Stderr, "Block %v:\n", hdrCid)
+				writeResults(os.Stderr, reports[hdrCid])
+			}
+			fmt.Printf("Validated %d block bodies against SHACL shapes\n", len(reports))
+			if len(failed) > 0 {
+				return fmt.Errorf("%d block bodies do not conform to the SHACL shapes", len(failed))
+			}
+			return nil
+		},
+	}
+
+}
+
+// withOutput calls the function with the output file, or with standard output if no file is given.
+func withOutput(outputFile string, f func(io.Writer) error) error {
+	if outputFile == "" {
+		return f(os.Stdout)
+	}
+	file, err := os.OpenFile(outputFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return f(file)
+}
+
+// readTrust reads a JSON object of trust weights, keyed by issuer DIDs or keyring aliases.
+func readTrust(trustFile string) (rdf.Trust, error) {
+	trustBytes, err := os.ReadFile(trustFile)
+	if err != nil {
+		return nil, err
+	}
+	var weights map[string]float64
+	err = json.Unmarshal(trustBytes, &weights)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust file: %w", err)
+	}
+	trust := rdf.Trust{}
+	for issuer, weight := range weights {
+		if weight < 0 || weight > 1 {
+			return nil, fmt.Errorf("trust for %v is not between 0 and 1: %v", issuer, weight)
+		}
+		did, err := resolveDid(issuer)
+		if err != nil {
+			return nil, err
+		}
+		trust[did] = weight
+	}
+	return trust, nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/functionally/nacatgunma/key"
+)
+
+// The environment variable holding the passphrase for encrypted private key files.
+const passphraseEnv = "NACATGUNMA_PASSPHRASE"
+
+func readPassphrase(prompt string, confirm bool) ([]byte, error) {
+	if passphrase, okay := os.LookupEnv(passphraseEnv); okay {
+		return []byte(passphrase), nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("passphrase required: set %v or run interactively", passphraseEnv)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Confirm passphrase: ")
+		again, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+func readPrivateKey(keyFile string) (key.Key, error) {
+	return key.ReadPrivateKeyWithPassphrase(keyFile, func() ([]byte, error) {
+		return readPassphrase(fmt.Sprintf("Passphrase for %v: ", keyFile), false)
+	})
+}
+
+func writePrivateKey(k key.Key, keyFile string, encrypt bool) error {
+	if !encrypt {
+		return key.WritePrivateKey(k, keyFile)
+	}
+	passphrase, err := readPassphrase(fmt.Sprintf("New passphrase for %v: ", keyFile), true)
+	if err != nil {
+		return err
+	}
+	return key.WriteEncryptedPrivateKey(k, keyFile, passphrase)
+}
+
+func readKeyShare(shareFile string) (*key.KeyShareBls12381, error) {
+	return key.ReadKeyShareWithPassphrase(shareFile, func() ([]byte, error) {
+		return readPassphrase(fmt.Sprintf("Passphrase for %v: ", shareFile), false)
+	})
+}
+
+func writeKeyShare(share *key.KeyShareBls12381, shareFile string, encrypt bool) error {
+	if !encrypt {
+		return key.WriteKeyShare(share, shareFile)
+	}
+	passphrase, err := readPassphrase(fmt.Sprintf("New passphrase for %v: ", shareFile), true)
+	if err != nil {
+		return err
+	}
+	return key.WriteEncryptedKeyShare(share, shareFile, passphrase)
+}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
+	"github.com/functionally/nacatgunma/header"
 	"github.com/functionally/nacatgunma/ipfs"
 	"github.com/functionally/nacatgunma/rdf"
 	"github.com/urfave/cli/v2"
@@ -15,29 +18,42 @@ func rdfCmd() *cli.Command {
 	var bodyFile string
 	var baseUri string
 	var format string
+	var contextFile string
+	var canonicalize bool
 
 	return &cli.Command{
 		Name:  "rdf",
-		Usage: "Build a body of RDF N-quads.",
+		Usage: "Build a body of RDF N-Quads, N-Triples, Turtle, or TriG.",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:        "rdf-file",
 				Required:    true,
-				Usage:       "Input file of RDF N-quads",
+				Usage:       "Input file of RDF",
 				Destination: &rdfFile,
 			},
 			&cli.StringFlag{
 				Name:        "base-uri",
 				Value:       "",
-				Usage:       "Base URI of the RDF",
+				Usage:       "Base URI for relative IRIs in the RDF, which are stored as absolute IRIs",
 				Destination: &baseUri,
 			},
 			&cli.StringFlag{
 				Name:        "format",
-				Value:       "application/n-quads",
-				Usage:       "MIME type of the RDF format",
+				Value:       "",
+				Usage:       "Format of the RDF: nquads, ntriples, turtle, trig, or a MIME type, instead of detecting it from the .nq, .nt, .ttl, or .trig extension",
 				Destination: &format,
 			},
+			&cli.StringFlag{
+				Name:        "context-file",
+				Usage:       "Input file for a fixed JSON-LD context, instead of generating one from the predicates",
+				Destination: &contextFile,
+			},
+			&cli.BoolFlag{
+				Name:        "canonicalize",
+				Value:       false,
+				Usage:       "Canonicalize the RDF with RDFC-1.0 (URDNA2015), so that equivalent graphs yield the same body",
+				Destination: &canonicalize,
+			},
 			&cli.StringFlag{
 				Name:        "body-file",
 				Required:    true,
@@ -45,8 +61,21 @@ func rdfCmd() *cli.Command {
 				Destination: &bodyFile,
 			},
 		},
-		Action: func(*cli.Context) error {
-			rdf, err := rdf.ReadRdf(rdfFile, baseUri, format)
+		Action: func(ctx *cli.Context) error {
+			var context map[string]interface{}
+			if ctx.IsSet("context-file") {
+				var err error
+				context, err = rdf.ReadContext(contextFile)
+				if err != nil {
+					return err
+				}
+			}
+			rdf, err := rdf.ReadRdfWithOptions(rdfFile, rdf.ReadOptions{
+				BaseURI:      baseUri,
+				Format:       format,
+				Context:      context,
+				Canonicalize: canonicalize,
+			})
 			if err != nil {
 				return err
 			}
@@ -68,3 +97,276 @@ func rdfCmd() *cli.Command {
 	}
 
 }
+
+func rdfExportCmd() *cli.Command {
+
+	var bodyFile string
+	var baseUri string
+	var format string
+	var outputFile string
+
+	return &cli.Command{
+		Name:  "rdf-export",
+		Usage: "Export a JSON-LD body, as built by \"body rdf\", as RDF.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "body-file",
+				Required:    true,
+				Usage:       "Input file for the block body",
+				Destination: &bodyFile,
+			},
+			&cli.StringFlag{
+				Name:        "base-uri",
+				Value:       "",
+				Usage:       "Base URI for relative IRIs in the body, instead of the body's CID",
+				Destination: &baseUri,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Value:       rdf.TurtleExport,
+				Usage:       "Output format: turtle, trig, nquads, or jsonld-expanded",
+				Destination: &format,
+			},
+			&cli.StringFlag{
+				Name:        "output-file",
+				Usage:       "Output file for the RDF, instead of standard output",
+				Destination: &outputFile,
+			},
+		},
+		Action: func(*cli.Context) error {
+			bodyBytes, err := os.ReadFile(bodyFile)
+			if err != nil {
+				return err
+			}
+			body, err := ipfs.DecodeFromDagCbor(bodyBytes)
+			if err != nil {
+				return err
+			}
+			if baseUri == "" {
+				bodyCid, err := ipfs.CidV1(bodyBytes)
+				if err != nil {
+					return err
+				}
+				baseUri = rdf.BodyBaseURI(*bodyCid)
+			}
+			return withOutput(outputFile, func(w io.Writer) error {
+				return rdf.WriteBody(w, body, baseUri, format)
+			})
+		},
+	}
+
+}
+
+func rdfValidateCmd() *cli.Command {
+
+	var bodyFile string
+	var headerFile string
+	var schemaURI string
+	var mediaType string
+	var baseUri string
+	var shapesFile string
+	var shapesMap string
+	var format string
+
+	return &cli.Command{
+		Name:  "validate",
+		Usage: "Validate a JSON-LD body against SHACL shapes.",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "body-file",
+				Required:    true,
+				Usage:       "Input file for the block body",
+				Destination: &bodyFile,
+			},
+			&cli.StringFlag{
+				Name:        "header-file",
+				Required:    false,
+				Usage:       "Input file for the block header, whose body, schema, and media type the body must match",
+				Destination: &headerFile,
+			},
+			&cli.StringFlag{
+				Name:        "schema",
+				Value:       rdf.JsonLdCborSchema,
+				Usage:       "Schema for the block body, if there is no --header-file",
+				Destination: &schemaURI,
+			},
+			&cli.StringFlag{
+				Name:        "media-type",
+				Value:       rdf.DagCborMediaType,
+				Usage:       "Media type for the block body, if there is no --header-file",
+				Destination: &mediaType,
+			},
+			&cli.StringFlag{
+				Name:        "base-uri",
+				Value:       "",
+				Usage:       "Base URI for relative IRIs in the body, instead of the body's CID",
+				Destination: &baseUri,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Value:       "text",
+				Usage:       "Output format for the validation report: text or json",
+				Destination: &format,
+			},
+		}, shapesFlags(&shapesFile, &shapesMap)...),
+		Action: func(*cli.Context) error {
+			catalog, err := readShapesCatalog(shapesFile, shapesMap)
+			if err != nil {
+				return err
+			} else if catalog == nil {
+				return fmt.Errorf("one of --shapes-file or --shapes-map must be specified")
+			}
+			bodyBytes, err := os.ReadFile(bodyFile)
+			if err != nil {
+				return err
+			}
+			if headerFile != "" {
+				headerBytes, err := os.ReadFile(headerFile)
+				if err != nil {
+					return err
+				}
+				hdr, err := header.UnmarshalHeader(headerBytes)
+				if err != nil {
+					return err
+				}
+				err = checkBodyCid(bodyBytes, &hdr.Payload)
+				if err != nil {
+					return err
+				}
+				schemaURI = hdr.Payload.SchemaURI
+				mediaType = hdr.Payload.MediaType
+				if baseUri == "" {
+					baseUri = rdf.BodyBaseURI(hdr.Payload.Body)
+				}
+			} else if baseUri == "" {
+				bodyCid, err := ipfs.CidV1(bodyBytes)
+				if err != nil {
+					return err
+				}
+				baseUri = rdf.BodyBaseURI(*bodyCid)
+			}
+			report, err := validateBody(bodyBytes, schemaURI, mediaType, baseUri, catalog)
+			if err != nil {
+				return err
+			}
+			switch format {
+			case "text":
+				if report.Conforms {
+					fmt.Println("Conforms")
+				}
+				writeResults(os.Stdout, report)
+			case "json":
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetEscapeHTML(false)
+				encoder.SetIndent("", "  ")
+				err = encoder.Encode(report)
+				if err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unsupported format: %v", format)
+			}
+			if !report.Conforms {
+				return fmt.Errorf("body does not conform to the SHACL shapes for %v", schemaURI)
+			}
+			return nil
+		},
+	}
+
+}
+
+// shapesFlags lets a command validate JSON-LD bodies against SHACL shapes, taken either
+// from one file or from a mapping of schema URIs to files.
+func shapesFlags(shapesFile *string, shapesMap *string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "shapes-file",
+			Required:    false,
+			Usage:       "Input file of SHACL shapes for validating JSON-LD bodies",
+			Destination: shapesFile,
+		},
+		&cli.StringFlag{
+			Name:        "shapes-map",
+			Required:    false,
+			Usage:       "Input JSON file mapping schema URIs to SHACL shapes files, instead of --shapes-file",
+			Destination: shapesMap,
+		},
+	}
+}
+
+// readShapesCatalog returns nil if no shapes were specified.
+func readShapesCatalog(shapesFile string, shapesMap string) (*rdf.ShapesCatalog, error) {
+	if shapesFile != "" && shapesMap != "" {
+		return nil, fmt.Errorf("at most one of --shapes-file or --shapes-map may be specified")
+	}
+	if shapesFile != "" {
+		shapes, err := rdf.ReadShapes(shapesFile)
+		if err != nil {
+			return nil, err
+		}
+		return rdf.NewShapesCatalog(shapes), nil
+	}
+	if shapesMap != "" {
+		return rdf.ReadShapesCatalog(shapesMap)
+	}
+	return nil, nil
+}
+
+func validateBody(bodyBytes []byte, schemaURI string, mediaType string, baseUri string, catalog *rdf.ShapesCatalog) (*rdf.Report, error) {
+	if !rdf.IsRdfBody(schemaURI, mediaType) {
+		return nil, fmt.Errorf("body with schema %v and media type %v is not JSON-LD", schemaURI, mediaType)
+	}
+	shapes, err := catalog.Lookup(schemaURI)
+	if err != nil {
+		return nil, err
+	} else if shapes == nil {
+		return nil, fmt.Errorf("no SHACL shapes for schema %v", schemaURI)
+	}
+	body, err := rdf.DecodeBody(bodyBytes, mediaType)
+	if err != nil {
+		return nil, err
+	}
+	return shapes.ValidateBody(body, baseUri)
+}
+
+// validateBodyFile checks that a body file matches the header payload and conforms to
+// the SHACL shapes for its schema, writing any validation results to standard error.
+func validateBodyFile(bodyFile string, payload *header.Payload, catalog *rdf.ShapesCatalog) error {
+	if bodyFile == "" {
+		return fmt.Errorf("--body-file must be specified to validate the body")
+	}
+	bodyBytes, err := os.ReadFile(bodyFile)
+	if err != nil {
+		return err
+	}
+	err = checkBodyCid(bodyBytes, payload)
+	if err != nil {
+		return err
+	}
+	report, err := validateBody(bodyBytes, payload.SchemaURI, payload.MediaType, rdf.BodyBaseURI(payload.Body), catalog)
+	if err != nil {
+		return err
+	}
+	if !report.Conforms {
+		writeResults(os.Stderr, report)
+		return fmt.Errorf("body does not conform to the SHACL shapes for %v", payload.SchemaURI)
+	}
+	return nil
+}
+
+func checkBodyCid(bodyBytes []byte, payload *header.Payload) error {
+	bodyCid, err := payload.Body.Prefix().Sum(bodyBytes)
+	if err != nil {
+		return err
+	}
+	if !bodyCid.Equals(payload.Body) {
+		return fmt.Errorf("body has CID %v but the header has %v", bodyCid, payload.Body)
+	}
+	return nil
+}
+
+func writeResults(w io.Writer, report *rdf.Report) {
+	for _, result := range report.Results {
+		fmt.Fprintln(w, result)
+	}
+}
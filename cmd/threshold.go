@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/functionally/nacatgunma/key"
+	"github.com/urfave/cli/v2"
+)
+
+func keyThresholdCmds() *cli.Command {
+	return &cli.Command{
+		Name:  "threshold",
+		Usage: "Threshold BLS12-381 signing subcommands",
+		Subcommands: []*cli.Command{
+			keyThresholdCombineCmd(),
+			keyThresholdDealCmd(),
+			keyThresholdSignCmd(),
+		},
+	}
+}
+
+func keyThresholdDealCmd() *cli.Command {
+
+	var threshold int
+	var shares int
+	var sharePrefix string
+	var encrypt bool
+
+	return &cli.Command{
+		Name:  "deal",
+		Usage: "Deal shares of a new BLS12-381 group key, printing the DID of the group.",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:        "threshold",
+				Required:    true,
+				Usage:       "The number of shares required to sign",
+				Destination: &threshold,
+			},
+			&cli.IntFlag{
+				Name:        "shares",
+				Required:    true,
+				Usage:       "The number of shares to deal",
+				Destination: &shares,
+			},
+			&cli.StringFlag{
+				Name:        "share-prefix",
+				Required:    true,
+				Usage:       "Prefix for the output share files, which are suffixed by \"-<index>.pem\"",
+				Destination: &sharePrefix,
+			},
+			&cli.BoolFlag{
+				Name:        "encrypt",
+				Value:       false,
+				Usage:       "Encrypt each share with a passphrase, read from $" + passphraseEnv + " or prompted",
+				Destination: &encrypt,
+			},
+		},
+		Action: func(*cli.Context) error {
+			dealt, err := key.DealBls12381(threshold, shares)
+			if err != nil {
+				return err
+			}
+			for _, share := range dealt {
+				err = writeKeyShare(share, fmt.Sprintf("%s-%d.pem", sharePrefix, share.Index), encrypt)
+				if err != nil {
+					return err
+				}
+			}
+			fmt.Println(dealt[0].GroupDid())
+			return nil
+		},
+	}
+
+}
+
+func keyThresholdSignCmd() *cli.Command {
+
+	var shareFile string
+	var inputFile string
+	var context string
+	var partialFile string
+
+	return &cli.Command{
+		Name:  "sign",
+		Usage: "Make a partial signature with a share of a group key.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "share-file",
+				Required:    true,
+				Usage:       "Input file for the key share",
+				Destination: &shareFile,
+			},
+			&cli.StringFlag{
+				Name:        "input-file",
+				Required:    true,
+				Usage:       "Input file for the message, such as a header payload",
+				Destination: &inputFile,
+			},
+			&cli.StringFlag{
+				Name:        "context",
+				Required:    false,
+				Usage:       "The signature context, defaulting to the DID of the group as for header signatures",
+				Destination: &context,
+			},
+			&cli.StringFlag{
+				Name:        "partial-file",
+				Required:    true,
+				Usage:       "Output JSON file for the partial signature",
+				Destination: &partialFile,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			share, err := readKeyShare(shareFile)
+			if err != nil {
+				return err
+			}
+			message, err := os.ReadFile(inputFile)
+			if err != nil {
+				return err
+			}
+			if !ctx.IsSet("context") {
+				context = share.GroupDid()
+			}
+			partial, err := share.SignPartial(message, context)
+			if err != nil {
+				return err
+			}
+			partialBytes, err := json.MarshalIndent(partial, "", "  ")
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(partialFile, partialBytes, 0644)
+		},
+	}
+
+}
+
+func keyThresholdCombineCmd() *cli.Command {
+
+	var partialFiles cli.StringSlice
+	var inputFile string
+	var context string
+	var signatureFile string
+
+	return &cli.Command{
+		Name:  "combine",
+		Usage: "Combine partial signatures into a signature by the group.",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:        "partial-file",
+				Required:    true,
+				Usage:       "Input JSON file for a partial signature",
+				Destination: &partialFiles,
+			},
+			&cli.StringFlag{
+				Name:        "input-file",
+				Required:    true,
+				Usage:       "Input file for the signed message",
+				Destination: &inputFile,
+			},
+			&cli.StringFlag{
+				Name:        "context",
+				Required:    false,
+				Usage:       "The signature context, defaulting to the DID of the group as for header signatures",
+				Destination: &context,
+			},
+			&cli.StringFlag{
+				Name:        "signature-file",
+				Required:    true,
+				Usage:       "Output file for the combined signature",
+				Destination: &signatureFile,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			var partials []key.PartialSignature
+			for _, partialFile := range partialFiles.Value() {
+				partialBytes, err := os.ReadFile(partialFile)
+				if err != nil {
+					return err
+				}
+				var partial key.PartialSignature
+				err = json.Unmarshal(partialBytes, &partial)
+				if err != nil {
+					return err
+				}
+				partials = append(partials, partial)
+			}
+			signature, err := key.CombineBls12381(partials)
+			if err != nil {
+				return err
+			}
+			message, err := os.ReadFile(inputFile)
+			if err != nil {
+				return err
+			}
+			group := partials[0].Group
+			if !ctx.IsSet("context") {
+				context = group
+			}
+			err = key.Verify(group, signature, message, context)
+			if err != nil {
+				return fmt.Errorf("combined signature failed verification, perhaps too few shares: %w", err)
+			}
+			err = os.WriteFile(signatureFile, signature, 0644)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Combined signature by %s\n", group)
+			return nil
+		},
+	}
+
+}
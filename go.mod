@@ -5,8 +5,10 @@ go 1.23.6
 toolchain go1.23.8
 
 require (
+	filippo.io/edwards25519 v1.1.0
 	github.com/blinklabs-io/gouroboros v0.120.1
 	github.com/cayleygraph/quad v1.3.0
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.0
 	github.com/ipfs/go-cid v0.5.0
 	github.com/ipfs/go-ipfs-api v0.7.0
 	github.com/ipld/go-ipld-prime v0.21.0
@@ -17,13 +19,15 @@ require (
 	github.com/multiformats/go-multihash v0.2.3
 	github.com/piprate/json-gold v0.5.1-0.20230111113000-6ddbe6e6f19f
 	github.com/trustbloc/did-go v1.3.1
+	github.com/trustbloc/kms-go v1.2.1
+	github.com/tyler-smith/go-bip39 v1.1.0
 	github.com/urfave/cli/v2 v2.27.6
 	golang.org/x/crypto v0.39.0
+	golang.org/x/term v0.32.0
 	gonum.org/v1/gonum v0.16.0
 )
 
 require (
-	filippo.io/edwards25519 v1.1.0 // indirect
 	github.com/IBM/mathlib v0.0.3-0.20231011094432-44ee0eb539da // indirect
 	github.com/benbjohnson/clock v1.3.0 // indirect
 	github.com/bits-and-blooms/bitset v1.17.0 // indirect
@@ -35,7 +39,6 @@ require (
 	github.com/consensys/gnark-crypto v0.14.0 // indirect
 	github.com/cpuguy83/go-md2man/v2 v2.0.5 // indirect
 	github.com/crackcomm/go-gitignore v0.0.0-20170627025303-887ab5e44cc3 // indirect
-	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.0 // indirect
 	github.com/fxamacker/cbor/v2 v2.8.0 // indirect
 	github.com/go-jose/go-jose/v3 v3.0.4 // indirect
 	github.com/goccy/go-json v0.10.3 // indirect
@@ -71,8 +74,8 @@ require (
 	github.com/spaolacci/murmur3 v1.1.0 // indirect
 	github.com/teserakt-io/golang-ed25519 v0.0.0-20210104091850-3888c087a4c8 // indirect
 	github.com/trustbloc/bbs-signature-go v1.0.2 // indirect
-	github.com/trustbloc/kms-go v1.2.1 // indirect
 	github.com/utxorpc/go-codegen v0.16.0 // indirect
+	github.com/valyala/fastjson v1.6.4 // indirect
 	github.com/x448/float16 v0.8.4 // indirect
 	github.com/xeipuuv/gojsonpointer v0.0.0-20190905194746-02993c407bfb // indirect
 	github.com/xeipuuv/gojsonreference v0.0.0-20180127040603-bd5ef7bd5415 // indirect
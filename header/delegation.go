@@ -0,0 +1,200 @@
+package header
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+
+	"github.com/functionally/nacatgunma/key"
+)
+
+// The signature context for delegations, which separates them from header signatures.
+const delegationContext = "nacatgunma-delegation"
+
+// A Delegation grants the audience the right to issue headers on behalf of the issuer,
+// optionally restricted to a schema URI, a media type, or an expiry time. The proof,
+// if present, is the delegation that granted the issuer its own authority.
+type Delegation struct {
+	Issuer    string
+	Audience  string
+	SchemaURI string
+	MediaType string
+	Expiry    int64
+	Proof     *Delegation
+	Signature []byte
+}
+
+func NewDelegation(k key.Key, audience string, schemaURI string, mediaType string, expiry int64, proof *Delegation) (*Delegation, error) {
+	issuer := key.Did(k)
+	if proof != nil && proof.Audience != issuer {
+		return nil, fmt.Errorf("proof was delegated to %v instead of %v", proof.Audience, issuer)
+	}
+	delegation := &Delegation{
+		Issuer:    issuer,
+		Audience:  audience,
+		SchemaURI: schemaURI,
+		MediaType: mediaType,
+		Expiry:    expiry,
+		Proof:     proof,
+	}
+	bytes, err := delegation.marshalUnsigned()
+	if err != nil {
+		return nil, err
+	}
+	delegation.Signature, err = k.Sign(bytes, delegationContext)
+	if err != nil {
+		return nil, err
+	}
+	return delegation, nil
+}
+
+func (delegation *Delegation) buildNode(signed bool) datamodel.Node {
+	return fluent.MustBuildMap(basicnode.Prototype__Any{}, 7,
+		func(assembler fluent.MapAssembler) {
+			assembler.AssembleEntry("Issuer").AssignString(delegation.Issuer)
+			assembler.AssembleEntry("Audience").AssignString(delegation.Audience)
+			assembler.AssembleEntry("Schema").AssignString(delegation.SchemaURI)
+			assembler.AssembleEntry("MediaType").AssignString(delegation.MediaType)
+			assembler.AssembleEntry("Expiry").AssignInt(delegation.Expiry)
+			if delegation.Proof != nil {
+				assembler.AssembleEntry("Proof").AssignNode(delegation.Proof.MakeNode())
+			}
+			if signed {
+				assembler.AssembleEntry("Signature").AssignBytes(delegation.Signature)
+			}
+		})
+}
+
+func (delegation *Delegation) MakeNode() datamodel.Node {
+	return delegation.buildNode(true)
+}
+
+func (delegation *Delegation) Marshal() ([]byte, error) {
+	var buffer bytes.Buffer
+	err := dagcbor.Encode(delegation.MakeNode(), &buffer)
+	return buffer.Bytes(), err
+}
+
+func (delegation *Delegation) marshalUnsigned() ([]byte, error) {
+	var buffer bytes.Buffer
+	err := dagcbor.Encode(delegation.buildNode(false), &buffer)
+	return buffer.Bytes(), err
+}
+
+func decodeDelegation(node ipld.Node) (*Delegation, error) {
+	delegation := &Delegation{}
+	if v, err := node.LookupByString("Issuer"); err == nil {
+		s, err := v.AsString()
+		if err != nil {
+			return nil, err
+		}
+		delegation.Issuer = s
+	}
+	if v, err := node.LookupByString("Audience"); err == nil {
+		s, err := v.AsString()
+		if err != nil {
+			return nil, err
+		}
+		delegation.Audience = s
+	}
+	if v, err := node.LookupByString("Schema"); err == nil {
+		s, err := v.AsString()
+		if err != nil {
+			return nil, err
+		}
+		delegation.SchemaURI = s
+	}
+	if v, err := node.LookupByString("MediaType"); err == nil {
+		s, err := v.AsString()
+		if err != nil {
+			return nil, err
+		}
+		delegation.MediaType = s
+	}
+	if v, err := node.LookupByString("Expiry"); err == nil {
+		expiry, err := v.AsInt()
+		if err != nil {
+			return nil, err
+		}
+		delegation.Expiry = expiry
+	}
+	if v, err := node.LookupByString("Proof"); err == nil {
+		proof, err := decodeDelegation(v)
+		if err != nil {
+			return nil, err
+		}
+		delegation.Proof = proof
+	}
+	if v, err := node.LookupByString("Signature"); err == nil {
+		b, err := v.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		delegation.Signature = b
+	}
+	return delegation, nil
+}
+
+func UnmarshalDelegation(data []byte) (*Delegation, error) {
+	nb := basicnode.Prototype__Any{}.NewBuilder()
+	if err := dagcbor.Decode(nb, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return decodeDelegation(nb.Build())
+}
+
+// Root returns the DID at the start of the delegation chain.
+func (delegation *Delegation) Root() string {
+	for delegation.Proof != nil {
+		delegation = delegation.Proof
+	}
+	return delegation.Issuer
+}
+
+// Verify checks the signatures, linkage, and expiry of every delegation in the chain.
+func (delegation *Delegation) Verify(now time.Time) error {
+	for d := delegation; d != nil; d = d.Proof {
+		if d.Proof != nil && d.Proof.Audience != d.Issuer {
+			return fmt.Errorf("delegation by %v is not supported by its proof", d.Issuer)
+		}
+		if d.Expiry != 0 && now.Unix() > d.Expiry {
+			return fmt.Errorf("delegation by %v expired at %v", d.Issuer, time.Unix(d.Expiry, 0).UTC())
+		}
+		bytes, err := d.marshalUnsigned()
+		if err != nil {
+			return err
+		}
+		err = key.Verify(d.Issuer, d.Signature, bytes, delegationContext)
+		if err != nil {
+			return fmt.Errorf("delegation by %v failed verification: %w", d.Issuer, err)
+		}
+	}
+	return nil
+}
+
+// Authorize checks that the delegation chain permits its audience to issue the payload
+// under the identity of the issuer, returning the DID that must have signed the payload.
+func (delegation *Delegation) Authorize(issuer string, payload *Payload, now time.Time) (string, error) {
+	if delegation.Root() != issuer {
+		return "", fmt.Errorf("delegation originates from %v instead of %v", delegation.Root(), issuer)
+	}
+	err := delegation.Verify(now)
+	if err != nil {
+		return "", err
+	}
+	for d := delegation; d != nil; d = d.Proof {
+		if d.SchemaURI != "" && d.SchemaURI != payload.SchemaURI {
+			return "", fmt.Errorf("delegation by %v does not permit schema %v", d.Issuer, payload.SchemaURI)
+		}
+		if d.MediaType != "" && d.MediaType != payload.MediaType {
+			return "", fmt.Errorf("delegation by %v does not permit media type %v", d.Issuer, payload.MediaType)
+		}
+	}
+	return delegation.Audience, nil
+}
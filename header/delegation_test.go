@@ -0,0 +1,165 @@
+package header
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/functionally/nacatgunma/key"
+)
+
+func makePayload(schemaURI string, mediaType string) *Payload {
+	body, _ := cid.Parse("bafyreih3lbpdqibixvdr3twiqwqrx3tgxbcwuooaq6ieyxzjzkw5zoxb3m")
+	return &Payload{
+		Version:   1,
+		Body:      body,
+		SchemaURI: schemaURI,
+		MediaType: mediaType,
+	}
+}
+
+func roundTrip(hdr *Header, t *testing.T) *Header {
+	hdrBytes, err := hdr.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hdr1, err := UnmarshalHeader(hdrBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hdr1
+}
+
+func TestVerify(t *testing.T) {
+	k, _ := key.GenerateKey(key.Ed25519)
+	hdr, err := makePayload("", "").Sign(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	okay, err := roundTrip(hdr, t).Verify()
+	if !okay || err != nil {
+		t.Errorf("header failed verification: %v", err)
+	}
+}
+
+func TestDelegation(t *testing.T) {
+	root, _ := key.GenerateKey(key.Ed25519)
+	team, _ := key.GenerateKey(key.Bls12381)
+	ci, _ := key.GenerateKey(key.Ed25519)
+	other, _ := key.GenerateKey(key.Ed25519)
+	now := time.Now()
+	expiry := now.Add(time.Hour).Unix()
+	d0, err := NewDelegation(root, key.Did(team), "urn:schema", "", expiry, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d1, err := NewDelegation(team, key.Did(ci), "", "text/turtle", 0, d0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Chain", func(t *testing.T) {
+		hdr, err := makePayload("urn:schema", "text/turtle").SignDelegated(ci, d1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hdr1 := roundTrip(hdr, t)
+		if hdr1.Issuer != key.Did(root) {
+			t.Errorf("incorrect issuer: %v", hdr1.Issuer)
+		}
+		okay, err := hdr1.VerifyAt(now)
+		if !okay || err != nil {
+			t.Errorf("delegated header failed verification: %v", err)
+		}
+		okay, _ = hdr1.VerifyAt(now.Add(2 * time.Hour))
+		if okay {
+			t.Error("expired delegation verified")
+		}
+	})
+
+	t.Run("Timestamp", func(t *testing.T) {
+		expired, err := NewDelegation(root, key.Did(ci), "", "", now.Add(-time.Hour).Unix(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload := makePayload("urn:schema", "text/turtle")
+		payload.Timestamp = now.Add(-2 * time.Hour).Unix()
+		if _, err := payload.SignDelegated(ci, expired); err == nil {
+			t.Error("backdated header signed with an expired delegation")
+		}
+		valid, err := NewDelegation(root, key.Did(ci), "", "", now.Add(time.Hour).Unix(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hdr, err := payload.SignDelegated(ci, valid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hdr1 := roundTrip(hdr, t)
+		if hdr1.Payload.Timestamp != payload.Timestamp {
+			t.Errorf("incorrect timestamp: %v", hdr1.Payload.Timestamp)
+		}
+		okay, err := hdr1.Verify()
+		if !okay || err != nil {
+			t.Errorf("delegated header failed verification: %v", err)
+		}
+		okay, _ = hdr1.VerifyAt(now.Add(2 * time.Hour))
+		if okay {
+			t.Error("backdated header verified after its delegation expired")
+		}
+	})
+
+	t.Run("Restrictions", func(t *testing.T) {
+		for _, payload := range []*Payload{
+			makePayload("urn:other", "text/turtle"),
+			makePayload("urn:schema", "application/json"),
+		} {
+			hdr, err := payload.SignDelegated(ci, d1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			okay, _ := roundTrip(hdr, t).VerifyAt(now)
+			if okay {
+				t.Errorf("restricted delegation verified: %v %v", payload.SchemaURI, payload.MediaType)
+			}
+		}
+	})
+
+	t.Run("Wrong audience", func(t *testing.T) {
+		_, err := makePayload("urn:schema", "text/turtle").SignDelegated(other, d1)
+		if err == nil {
+			t.Error("signed with delegation for another key")
+		}
+		_, err = NewDelegation(other, key.Did(ci), "", "", 0, d0)
+		if err == nil {
+			t.Error("delegated without holding the proof")
+		}
+	})
+
+	t.Run("Forged signer", func(t *testing.T) {
+		hdr, err := makePayload("urn:schema", "text/turtle").SignDelegated(ci, d1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		forged, _ := makePayload("urn:schema", "text/turtle").Sign(other)
+		hdr.Signature = forged.Signature
+		okay, _ := roundTrip(hdr, t).VerifyAt(now)
+		if okay {
+			t.Error("forged signature verified")
+		}
+	})
+
+	t.Run("Tampered delegation", func(t *testing.T) {
+		hdr, err := makePayload("urn:other", "text/turtle").SignDelegated(ci, d1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hdr1 := roundTrip(hdr, t)
+		hdr1.Delegation.Proof.SchemaURI = ""
+		okay, _ := hdr1.VerifyAt(now)
+		if okay {
+			t.Error("tampered delegation verified")
+		}
+	})
+}
@@ -2,6 +2,7 @@ package header
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/ipld/go-ipld-prime"
 	"github.com/ipld/go-ipld-prime/codec/dagcbor"
@@ -13,17 +14,22 @@ import (
 )
 
 type Header struct {
-	Payload   Payload
-	Issuer    string
-	Signature []byte
+	Payload    Payload
+	Issuer     string
+	Signature  []byte
+	Delegation *Delegation `json:",omitempty"`
 }
 
 func (header *Header) MakeNode() datamodel.Node {
-	return fluent.MustBuildMap(basicnode.Prototype__Any{}, 3,
+	return fluent.MustBuildMap(basicnode.Prototype__Any{}, 4,
 		func(assembler fluent.MapAssembler) {
 			assembler.AssembleEntry("Payload").AssignNode(header.Payload.MakeNode())
 			assembler.AssembleEntry("Issuer").AssignString(header.Issuer)
 			assembler.AssembleEntry("Signature").AssignBytes(header.Signature)
+			// Headers signed directly by the issuer omit the delegation, so their encoding is unchanged.
+			if header.Delegation != nil {
+				assembler.AssembleEntry("Delegation").AssignNode(header.Delegation.MakeNode())
+			}
 		})
 }
 
@@ -61,6 +67,13 @@ func decodeHeader(node ipld.Node) (*Header, error) {
 		}
 		header.Signature = b
 	}
+	if v, err := node.LookupByString("Delegation"); err == nil {
+		delegation, err := decodeDelegation(v)
+		if err != nil {
+			return nil, err
+		}
+		header.Delegation = delegation
+	}
 	return header, nil
 }
 
@@ -73,11 +86,25 @@ func UnmarshalHeader(data []byte) (*Header, error) {
 	return decodeHeader(node)
 }
 
+// Verify checks the header as of the current time. The timestamp in the payload is
+// chosen by the signer, so it cannot vouch for an unexpired delegation.
 func (header *Header) Verify() (bool, error) {
+	return header.VerifyAt(time.Now())
+}
+
+// VerifyAt checks the signature on the payload, and any delegation, as of the given time.
+func (header *Header) VerifyAt(now time.Time) (bool, error) {
 	bytes, err := header.Payload.Marshal()
 	if err != nil {
 		return false, err
 	}
-	err = key.Verify(header.Issuer, bytes, header.Signature, header.Issuer)
+	signer := header.Issuer
+	if header.Delegation != nil {
+		signer, err = header.Delegation.Authorize(header.Issuer, &header.Payload, now)
+		if err != nil {
+			return false, err
+		}
+	}
+	err = key.Verify(signer, header.Signature, bytes, header.Issuer)
 	return err == nil, err
 }
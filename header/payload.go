@@ -2,6 +2,8 @@ package header
 
 import (
 	"bytes"
+	"fmt"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipld/go-ipld-prime"
@@ -22,10 +24,11 @@ type Payload struct {
 	SchemaURI string
 	MediaType string
 	Comment   string
+	Timestamp int64
 }
 
 func (payload *Payload) MakeNode() datamodel.Node {
-	return fluent.MustBuildMap(basicnode.Prototype__Any{}, 6,
+	return fluent.MustBuildMap(basicnode.Prototype__Any{}, 8,
 		func(assembler fluent.MapAssembler) {
 			assembler.AssembleEntry("Version").AssignInt(payload.Version)
 			assembler.AssembleEntry("Accept").CreateList(2, func(la fluent.ListAssembler) {
@@ -42,6 +45,10 @@ func (payload *Payload) MakeNode() datamodel.Node {
 			assembler.AssembleEntry("Schema").AssignString(payload.SchemaURI)
 			assembler.AssembleEntry("MediaType").AssignString(payload.MediaType)
 			assembler.AssembleEntry("Comment").AssignString(payload.Comment)
+			// Payloads without a timestamp omit it, so their encoding is unchanged.
+			if payload.Timestamp != 0 {
+				assembler.AssembleEntry("Timestamp").AssignInt(payload.Timestamp)
+			}
 		})
 }
 
@@ -68,6 +75,59 @@ func (payload *Payload) Sign(k key.Key) (*Header, error) {
 	}, nil
 }
 
+// SignAs signs the payload on behalf of an issuer, such as a did:web, whose DID document
+// lists the public key of the signer.
+func (payload *Payload) SignAs(k key.Key, issuer string) (*Header, error) {
+	bytes, err := payload.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	s, err := k.Sign(bytes, issuer)
+	if err != nil {
+		return nil, err
+	}
+	err = key.Verify(issuer, s, bytes, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("key %v cannot sign for %v: %w", key.Did(k), issuer, err)
+	}
+	return &Header{
+		Payload:   *payload,
+		Issuer:    issuer,
+		Signature: s,
+	}, nil
+}
+
+// SignDelegated signs the payload with a key that holds a delegation, so that the
+// header is issued under the identity at the root of the delegation chain. The payload
+// is timestamped, if it is not already, and the delegation must not have expired.
+func (payload *Payload) SignDelegated(k key.Key, delegation *Delegation) (*Header, error) {
+	if delegation.Audience != key.Did(k) {
+		return nil, fmt.Errorf("delegation was granted to %v instead of %v", delegation.Audience, key.Did(k))
+	}
+	if payload.Timestamp == 0 {
+		payload.Timestamp = time.Now().Unix()
+	}
+	err := delegation.Verify(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := payload.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	issuer := delegation.Root()
+	s, err := k.Sign(bytes, issuer)
+	if err != nil {
+		return nil, err
+	}
+	return &Header{
+		Payload:    *payload,
+		Issuer:     issuer,
+		Signature:  s,
+		Delegation: delegation,
+	}, nil
+}
+
 func decodePayload(node ipld.Node) (*Payload, error) {
 	payload := &Payload{}
 	if v, err := node.LookupByString("Version"); err == nil {
@@ -133,5 +193,38 @@ func decodePayload(node ipld.Node) (*Payload, error) {
 		}
 		payload.Comment = s
 	}
+	if v, err := node.LookupByString("Timestamp"); err == nil {
+		timestamp, err := v.AsInt()
+		if err != nil {
+			return nil, err
+		}
+		payload.Timestamp = timestamp
+	}
 	return payload, nil
 }
+
+func UnmarshalPayload(data []byte) (*Payload, error) {
+	nb := basicnode.Prototype__Any{}.NewBuilder()
+	if err := dagcbor.Decode(nb, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return decodePayload(nb.Build())
+}
+
+// Assemble attaches a signature made elsewhere, such as a combined threshold signature,
+// to the payload, checking that it verifies under the issuer.
+func (payload *Payload) Assemble(issuer string, signature []byte) (*Header, error) {
+	bytes, err := payload.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	err = key.Verify(issuer, signature, bytes, issuer)
+	if err != nil {
+		return nil, err
+	}
+	return &Header{
+		Payload:   *payload,
+		Issuer:    issuer,
+		Signature: signature,
+	}, nil
+}
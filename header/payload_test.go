@@ -0,0 +1,25 @@
+package header
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/functionally/nacatgunma/key"
+)
+
+func TestSignAs(t *testing.T) {
+	k, _ := key.GenerateKey(key.Ed25519)
+	other, _ := key.GenerateKey(key.Ed25519)
+	issuer := "did:peer:0" + strings.TrimPrefix(key.Did(k), "did:key:")
+	hdr, err := makePayload("", "").SignAs(k, issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	okay, err := roundTrip(hdr, t).Verify()
+	if err != nil || !okay {
+		t.Errorf("header issued by %v failed verification: %v", issuer, err)
+	}
+	if _, err := makePayload("", "").SignAs(other, issuer); err == nil {
+		t.Error("key not listed by the issuer signed for it")
+	}
+}
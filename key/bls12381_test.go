@@ -47,3 +47,7 @@ func TestIO_Bls12381(t *testing.T) {
 func TestVerify_Bls12381(t *testing.T) {
 	testVerify(Bls12381, t)
 }
+
+func TestContextSeparation_Bls12381(t *testing.T) {
+	testContextSeparation(Bls12381, t)
+}
@@ -0,0 +1,44 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/functionally/nacatgunma/tgdh"
+)
+
+func TestBls12381G1_TgdhDid(t *testing.T) {
+	leaf, err := tgdh.GenerateLeaf()
+	if err != nil {
+		t.Fatal(err)
+	}
+	groupDid := leaf.Did()
+	keyType, pubBytes, err := PublicKeyFromDid(groupDid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyType != Bls12381G1 || didFromPublic(keyType, pubBytes) != groupDid {
+		t.Fatalf("incorrect key type or public key: %v", keyType)
+	}
+	resolution, err := ResolveDid(groupDid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := resolution.DIDDocument
+	if len(doc.KeyAgreement) != 1 || len(doc.AssertionMethod) != 0 || len(doc.Authentication) != 0 {
+		t.Error("G1 key is not solely for key agreement")
+	}
+	if _, err := resolution.JSONBytes(); err != nil {
+		t.Error(err)
+	}
+	jwkBytes, err := MarshalPublicJWK(groupDid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, did, err := UnmarshalJWK(jwkBytes)
+	if err != nil || k != nil || did != groupDid {
+		t.Errorf("JWK does not round trip: %v", err)
+	}
+	if err := Verify(groupDid, make([]byte, 48), []byte("message"), ""); err == nil {
+		t.Error("G1 key verified a signature")
+	}
+}
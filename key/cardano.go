@@ -0,0 +1,74 @@
+package key
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// A Cardano text envelope, as written by cardano-cli for signing keys.
+type textEnvelope struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	CborHex     string `json:"cborHex"`
+}
+
+func isTextEnvelope(data []byte) bool {
+	return strings.HasPrefix(strings.TrimSpace(string(data)), "{")
+}
+
+// decodeTextEnvelope reads Cardano Ed25519 signing keys, such as "PaymentSigningKeyShelley_ed25519",
+// and extended signing keys, such as "PaymentExtendedSigningKeyShelley_ed25519_bip32".
+func decodeTextEnvelope(data []byte) (Key, error) {
+	var envelope textEnvelope
+	err := json.Unmarshal(data, &envelope)
+	if err != nil {
+		return nil, fmt.Errorf("malformed text envelope: %w", err)
+	}
+	cborBytes, err := hex.DecodeString(envelope.CborHex)
+	if err != nil {
+		return nil, err
+	}
+	// The key is a CBOR byte string with a one-byte length.
+	if len(cborBytes) < 2 || cborBytes[0] != 0x58 || int(cborBytes[1]) != len(cborBytes)-2 {
+		return nil, fmt.Errorf("malformed CBOR in text envelope")
+	}
+	priBytes := cborBytes[2:]
+	switch {
+	case strings.HasSuffix(envelope.Type, "ExtendedSigningKeyShelley_ed25519_bip32"):
+		return fromBytesEd25519Extended(priBytes)
+	case strings.HasSuffix(envelope.Type, "SigningKeyShelley_ed25519"):
+		if len(priBytes) != ed25519.SeedSize {
+			return nil, fmt.Errorf("incorrect length of Ed25519 seed: %v", len(priBytes))
+		}
+		return makeEd25519(ed25519.NewKeyFromSeed(priBytes))
+	default:
+		return nil, fmt.Errorf("unsupported text envelope type: %v", envelope.Type)
+	}
+}
+
+// MarshalTextEnvelope serializes an Ed25519 key as a Cardano payment signing key.
+func MarshalTextEnvelope(k Key) ([]byte, error) {
+	var envelope textEnvelope
+	var priBytes []byte
+	switch k1 := k.(type) {
+	case *KeyEd25519:
+		envelope.Type = "PaymentSigningKeyShelley_ed25519"
+		envelope.Description = "Payment Signing Key"
+		priBytes = k1.Private.Seed()
+	case *KeyEd25519Extended:
+		envelope.Type = "PaymentExtendedSigningKeyShelley_ed25519_bip32"
+		envelope.Description = "Payment Signing Key"
+		priBytes = k1.bytes()
+	default:
+		return nil, fmt.Errorf("only Ed25519 keys can be Cardano signing keys")
+	}
+	envelope.CborHex = hex.EncodeToString(append([]byte{0x58, byte(len(priBytes))}, priBytes...))
+	envelopeBytes, err := json.MarshalIndent(envelope, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(envelopeBytes, '\n'), nil
+}
@@ -0,0 +1,102 @@
+package key
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func extendedFromSeed(t *testing.T, seed []byte) *KeyEd25519Extended {
+	h := sha512.Sum512(seed)
+	h[0] &= 248
+	h[31] &= 127
+	h[31] |= 64
+	k, err := makeEd25519Extended(h[:32], h[32:], bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestSign_Ed25519Extended(t *testing.T) {
+	k0, err := generateEd25519()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k1 := extendedFromSeed(t, k0.Private.Seed())
+	if Did(k0) != Did(k1) {
+		t.Fatal("extended key does not match seed")
+	}
+	message := []byte("message")
+	for _, context := range []string{"", Did(k0)} {
+		sig0, err := k0.Sign(message, context)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig1, err := k1.Sign(message, context)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig0, sig1) {
+			t.Errorf("extended signature differs for context %q", context)
+		}
+		if err := Verify(Did(k1), sig1, message, context); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestTextEnvelope(t *testing.T) {
+	dir := t.TempDir()
+	k0, err := generateEd25519()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []Key{k0, extendedFromSeed(t, k0.Private.Seed())} {
+		envelope, err := MarshalTextEnvelope(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := filepath.Join(dir, "payment.skey")
+		if err := os.WriteFile(filename, envelope, 0600); err != nil {
+			t.Fatal(err)
+		}
+		k1, err := ReadPrivateKey(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if Did(k1) != Did(k0) || !bytes.Equal(k1.PrivateBytes(), k.PrivateBytes()) {
+			t.Errorf("text envelope does not match for %T", k)
+		}
+		filename = filepath.Join(dir, "payment.pem")
+		if err := WritePrivateKey(k1, filename); err != nil {
+			t.Fatal(err)
+		}
+		k2, err := ReadPrivateKey(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if Did(k2) != Did(k0) || !bytes.Equal(k2.PrivateBytes(), k.PrivateBytes()) {
+			t.Errorf("PEM does not match for %T", k)
+		}
+	}
+}
+
+func TestTextEnvelope_Rfc8032(t *testing.T) {
+	// Test 1 of RFC 8032, section 7.1, wrapped as a cardano-cli payment signing key.
+	envelope := []byte(`{
+    "type": "PaymentSigningKeyShelley_ed25519",
+    "description": "Payment Signing Key",
+    "cborHex": "58209d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+}`)
+	k, err := decodeTextEnvelope(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(k.PublicBytes()) != "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a" {
+		t.Error("incorrect public key")
+	}
+}
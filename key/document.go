@@ -0,0 +1,150 @@
+package key
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/multiformats/go-multibase"
+)
+
+// A DidDocument is a DID document for publication, such as the did.json of a did:web.
+type DidDocument struct {
+	Context            []string             `json:"@context"`
+	ID                 string               `json:"id"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod"`
+	Authentication     []string             `json:"authentication,omitempty"`
+	AssertionMethod    []string             `json:"assertionMethod,omitempty"`
+	KeyAgreement       []string             `json:"keyAgreement,omitempty"`
+	Service            []Service            `json:"service,omitempty"`
+	useJWK             bool
+}
+
+type VerificationMethod struct {
+	ID                 string      `json:"id"`
+	Type               string      `json:"type"`
+	Controller         string      `json:"controller"`
+	PublicKeyMultibase string      `json:"publicKeyMultibase,omitempty"`
+	PublicKeyJwk       *jsonWebKey `json:"publicKeyJwk,omitempty"`
+}
+
+type Service struct {
+	ID              string `json:"id"`
+	Type            string `json:"type"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
+}
+
+// DidWeb returns the did:web for a domain, which may include a port, and an optional path.
+func DidWeb(domain string, path string) string {
+	id := "did:web:" + url.QueryEscape(domain)
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment != "" {
+			id += ":" + url.QueryEscape(segment)
+		}
+	}
+	return id
+}
+
+// NewDidDocument creates an empty DID document whose verification methods are either
+// multikeys or, if requested, JWKs. BLS12-381 keys remain multikeys, because they have no
+// registered JWK curves and common resolvers reject unregistered ones.
+func NewDidDocument(id string, useJWK bool) *DidDocument {
+	context := []string{"https://www.w3.org/ns/did/v1", "https://w3id.org/security/multikey/v1"}
+	if useJWK {
+		context = append(context, "https://w3id.org/security/suites/jws-2020/v1")
+	}
+	return &DidDocument{
+		Context: context,
+		ID:      id,
+		useJWK:  useJWK,
+	}
+}
+
+func (doc *DidDocument) addMethod(keyType KeyType, pubBytes []byte) (string, error) {
+	fingerprint, err := multibase.Encode(multibase.Base58BTC, append(prefixBytes(keyType), pubBytes...))
+	if err != nil {
+		return "", err
+	}
+	id := "#" + fingerprint
+	for _, vm := range doc.VerificationMethod {
+		if vm.ID == id {
+			return "", fmt.Errorf("duplicate key in DID document: %v", fingerprint)
+		}
+	}
+	vm := VerificationMethod{
+		ID:         id,
+		Controller: doc.ID,
+	}
+	if doc.useJWK && keyType != Bls12381 && keyType != Bls12381G1 {
+		vm.Type = "JsonWebKey2020"
+		vm.PublicKeyJwk, err = publicJWK(keyType, pubBytes)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		vm.Type = "Multikey"
+		vm.PublicKeyMultibase = fingerprint
+	}
+	doc.VerificationMethod = append(doc.VerificationMethod, vm)
+	return id, nil
+}
+
+// AddSigningKey lists the public key of a did:key for authentication and assertion,
+// so that it can issue headers on behalf of the document's DID.
+func (doc *DidDocument) AddSigningKey(didKey string) error {
+	keyType, pubBytes, err := PublicKeyFromDid(didKey)
+	if err != nil {
+		return err
+	}
+	if keyType == Bls12381G1 {
+		return fmt.Errorf("BLS12-381 G1 keys are only for key agreement")
+	}
+	id, err := doc.addMethod(keyType, pubBytes)
+	if err != nil {
+		return err
+	}
+	doc.Authentication = append(doc.Authentication, id)
+	doc.AssertionMethod = append(doc.AssertionMethod, id)
+	return nil
+}
+
+// AddKeyAgreementKey lists the BLS12-381 G1 public key of a did:key, such as that of a
+// TGDH group, for key agreement.
+func (doc *DidDocument) AddKeyAgreementKey(didKey string) error {
+	keyType, pubBytes, err := PublicKeyFromDid(didKey)
+	if err != nil {
+		return err
+	}
+	if keyType != Bls12381G1 {
+		return fmt.Errorf("only BLS12-381 G1 keys are for key agreement")
+	}
+	id, err := doc.addMethod(keyType, pubBytes)
+	if err != nil {
+		return err
+	}
+	doc.KeyAgreement = append(doc.KeyAgreement, id)
+	return nil
+}
+
+func (doc *DidDocument) AddService(serviceType string, endpoint string) {
+	id := "#" + strings.ToLower(serviceType)
+	count := 1
+	for _, service := range doc.Service {
+		if service.Type == serviceType {
+			count++
+		}
+	}
+	if count > 1 {
+		id += fmt.Sprintf("-%d", count)
+	}
+	doc.Service = append(doc.Service, Service{
+		ID:              id,
+		Type:            serviceType,
+		ServiceEndpoint: endpoint,
+	})
+}
+
+func (doc *DidDocument) Marshal() ([]byte, error) {
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,87 @@
+package key
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+func TestDidWeb(t *testing.T) {
+	if id := DidWeb("example.org", ""); id != "did:web:example.org" {
+		t.Errorf("incorrect did:web: %v", id)
+	}
+	if id := DidWeb("localhost:8443", "/users/alice/"); id != "did:web:localhost%3A8443:users:alice" {
+		t.Errorf("incorrect did:web: %v", id)
+	}
+}
+
+func TestDidDocument(t *testing.T) {
+	kEd, err := GenerateKey(Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kBls, err := GenerateKey(Bls12381)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kP256, err := GenerateKey(P256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g1 := bls12381.NewG1()
+	agreement := didFromPublic(Bls12381G1, g1.ToCompressed(g1.One()))
+	for _, useJWK := range []bool{false, true} {
+		var docBytes []byte
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(docBytes)
+		}))
+		host, err := url.Parse(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		didWeb := DidWeb(host.Host, "")
+		doc := NewDidDocument(didWeb, useJWK)
+		for _, k := range []Key{kEd, kBls, kP256} {
+			if err := doc.AddSigningKey(Did(k)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := doc.AddSigningKey(Did(kEd)); err == nil {
+			t.Error("duplicate key accepted")
+		}
+		if err := doc.AddSigningKey(agreement); err == nil {
+			t.Error("key agreement key accepted for signing")
+		}
+		if err := doc.AddKeyAgreementKey(agreement); err != nil {
+			t.Fatal(err)
+		}
+		doc.AddService("NacatgunmaIpfs", "https://ipfs.example.org")
+		docBytes, err = doc.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		DefaultRegistry.Register("web", &WebResolver{Client: server.Client()})
+		message := []byte("message")
+		for _, k := range []Key{kEd, kBls, kP256} {
+			sig, err := k.Sign(message, didWeb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Verify(didWeb, sig, message, didWeb); err != nil {
+				t.Errorf("key type %v failed verification with JWK %v: %v", k.keyType(), useJWK, err)
+			}
+		}
+		resolved, err := DefaultRegistry.Resolve(didWeb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resolved.KeyAgreement) != 1 || len(resolved.Service) != 1 {
+			t.Errorf("incorrect key agreement or services with JWK %v", useJWK)
+		}
+		server.Close()
+	}
+	DefaultRegistry.Register("web", NewWebResolver())
+}
@@ -0,0 +1,60 @@
+package key
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// The ECDSA key types sign a SHA-256 digest that binds the signature context to the
+// message in the same manner as Ed25519ctx, limiting the context to 255 bytes. Unlike
+// Ed25519, an empty context is still length-prefixed, so that no signature over a plain
+// digest can be replayed under a context.
+func digestEcdsa(message []byte, context string) ([]byte, error) {
+	if len(context) > 255 {
+		return nil, fmt.Errorf("context too long: %v", len(context))
+	}
+	hash := sha256.New()
+	hash.Write([]byte{byte(len(context))})
+	hash.Write([]byte(context))
+	hash.Write(message)
+	return hash.Sum(nil), nil
+}
+
+// ecdsaKey is implemented by the ECDSA key types, whose JWS algorithms, ES256 and
+// ES256K, sign the plain SHA-256 digest of the signing input.
+type ecdsaKey interface {
+	signDigest(digest []byte) ([]byte, error)
+}
+
+func verifyDigestEcdsa(keyType KeyType, pubBytes []byte, sig []byte, digest []byte) error {
+	switch keyType {
+	case Secp256k1:
+		return verifyDigestSecp256k1(pubBytes, sig, digest)
+	case P256:
+		pub, err := publicFromBytesP256(pubBytes)
+		if err != nil {
+			return err
+		}
+		return verifyDigestP256(pub, sig, digest)
+	default:
+		return fmt.Errorf("invalid ECDSA key type: %v", keyType)
+	}
+}
+
+// ECDSA signatures are serialized as the 64-byte concatenation of R and S, as in JWS.
+// S is at most half the order of the curve, since (R, N-S) would otherwise be a second
+// valid signature, and so a second CID, for the same header.
+func encodeEcdsa(r *big.Int, s *big.Int) []byte {
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return sig
+}
+
+func decodeEcdsa(sig []byte) (*big.Int, *big.Int, error) {
+	if len(sig) != 64 {
+		return nil, nil, fmt.Errorf("incorrect length of ECDSA signature: %v", len(sig))
+	}
+	return new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:]), nil
+}
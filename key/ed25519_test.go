@@ -45,3 +45,7 @@ func TestIO_Ed25519(t *testing.T) {
 func TestVerify_Ed25519(t *testing.T) {
 	testVerify(Ed25519, t)
 }
+
+func TestContextSeparation_Ed25519(t *testing.T) {
+	testContextSeparation(Ed25519, t)
+}
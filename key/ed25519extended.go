@@ -0,0 +1,112 @@
+package key
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"fmt"
+
+	"filippo.io/edwards25519"
+)
+
+// A KeyEd25519Extended is an Ed25519 key held in expanded form, as for the BIP32-Ed25519
+// keys of Cardano wallets, which have no seed. Its signatures verify as ordinary Ed25519.
+type KeyEd25519Extended struct {
+	Scalar    []byte
+	Prefix    []byte
+	Public    ed25519.PublicKey
+	ChainCode []byte
+}
+
+func makeEd25519Extended(scalar []byte, prefix []byte, chainCode []byte) (*KeyEd25519Extended, error) {
+	if len(scalar) != 32 || len(prefix) != 32 || len(chainCode) != 32 {
+		return nil, fmt.Errorf("incorrect length of extended Ed25519 private key")
+	}
+	s, err := scalarEd25519Extended(scalar)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyEd25519Extended{
+		Scalar:    scalar,
+		Prefix:    prefix,
+		Public:    new(edwards25519.Point).ScalarBaseMult(s).Bytes(),
+		ChainCode: chainCode,
+	}, nil
+}
+
+// The extended private key is serialized as in Cardano, with the scalar, prefix,
+// public key, and chain code concatenated.
+func fromBytesEd25519Extended(priBytes []byte) (*KeyEd25519Extended, error) {
+	if len(priBytes) != 128 {
+		return nil, fmt.Errorf("incorrect length of extended Ed25519 private key: %v", len(priBytes))
+	}
+	k, err := makeEd25519Extended(priBytes[:32], priBytes[32:64], priBytes[96:])
+	if err != nil {
+		return nil, err
+	}
+	if !k.Public.Equal(ed25519.PublicKey(priBytes[64:96])) {
+		return nil, fmt.Errorf("extended Ed25519 public key does not match private key")
+	}
+	return k, nil
+}
+
+func (k *KeyEd25519Extended) bytes() []byte {
+	bytes := append([]byte{}, k.Scalar...)
+	bytes = append(bytes, k.Prefix...)
+	bytes = append(bytes, k.Public...)
+	return append(bytes, k.ChainCode...)
+}
+
+func scalarEd25519Extended(scalar []byte) (*edwards25519.Scalar, error) {
+	// BIP32-Ed25519 scalars are not necessarily reduced, so reduce them modulo the group order.
+	wide := make([]byte, 64)
+	copy(wide, scalar)
+	return edwards25519.NewScalar().SetUniformBytes(wide)
+}
+
+func (k *KeyEd25519Extended) keyType() KeyType {
+	return Ed25519
+}
+
+func (k *KeyEd25519Extended) PrivateBytes() []byte {
+	return append(append([]byte{}, k.Scalar...), k.Prefix...)
+}
+
+func (k *KeyEd25519Extended) PublicBytes() []byte {
+	return k.Public
+}
+
+// Sign follows RFC 8032, using Ed25519ctx when the context is not empty, as does ed25519.PrivateKey.
+func (k *KeyEd25519Extended) Sign(message []byte, context string) ([]byte, error) {
+	if len(context) > 255 {
+		return nil, fmt.Errorf("context too long: %v", len(context))
+	}
+	var dom []byte
+	if context != "" {
+		dom = append([]byte("SigEd25519 no Ed25519 collisions\x00"), byte(len(context)))
+		dom = append(dom, context...)
+	}
+	s, err := scalarEd25519Extended(k.Scalar)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha512.New()
+	hash.Write(dom)
+	hash.Write(k.Prefix)
+	hash.Write(message)
+	r, err := edwards25519.NewScalar().SetUniformBytes(hash.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	R := new(edwards25519.Point).ScalarBaseMult(r).Bytes()
+	hash.Reset()
+	hash.Write(dom)
+	hash.Write(R)
+	hash.Write(k.Public)
+	hash.Write(message)
+	h, err := edwards25519.NewScalar().SetUniformBytes(hash.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	S := edwards25519.NewScalar().MultiplyAdd(h, s, r)
+	return append(R, S.Bytes()...), nil
+}
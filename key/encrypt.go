@@ -0,0 +1,120 @@
+package key
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted key files wrap the PEM block of the key in an scrypt and AES-256-GCM envelope,
+// recording the parameters in the PEM headers of a block whose type is prefixed by "ENCRYPTED".
+const encryptedPrefix = "ENCRYPTED "
+
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// The largest scrypt parameters accepted when decrypting, which bound the memory
+// (128 * N * r bytes, or 1 GiB) and time that a crafted key file can demand.
+var scryptLimits = map[string]int{
+	"N": 1 << 20,
+	"R": 8,
+	"P": 16,
+}
+
+func isEncrypted(block *pem.Block) bool {
+	return strings.HasPrefix(block.Type, encryptedPrefix)
+}
+
+func encryptBlock(block *pem.Block, passphrase []byte) (*pem.Block, error) {
+	salt := make([]byte, 32)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := envelopeCipher(passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	return &pem.Block{
+		Type: encryptedPrefix + block.Type,
+		Headers: map[string]string{
+			"Cipher": "AES-256-GCM",
+			"Kdf":    "scrypt",
+			"N":      strconv.Itoa(scryptN),
+			"R":      strconv.Itoa(scryptR),
+			"P":      strconv.Itoa(scryptP),
+			"Salt":   base64.StdEncoding.EncodeToString(salt),
+			"Nonce":  base64.StdEncoding.EncodeToString(nonce),
+		},
+		Bytes: aead.Seal(nil, nonce, block.Bytes, []byte(block.Type)),
+	}, nil
+}
+
+func decryptBlock(block *pem.Block, passphrase []byte) (*pem.Block, error) {
+	if block.Headers["Cipher"] != "AES-256-GCM" || block.Headers["Kdf"] != "scrypt" {
+		return nil, fmt.Errorf("unsupported key encryption: %v with %v", block.Headers["Cipher"], block.Headers["Kdf"])
+	}
+	params := make(map[string]int)
+	for _, name := range []string{"N", "R", "P"} {
+		value, err := strconv.Atoi(block.Headers[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid scrypt parameter %v: %w", name, err)
+		}
+		if value < 1 || value > scryptLimits[name] {
+			return nil, fmt.Errorf("scrypt parameter %v is outside the range 1 to %v: %v", name, scryptLimits[name], value)
+		}
+		params[name] = value
+	}
+	salt, err := base64.StdEncoding.DecodeString(block.Headers["Salt"])
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := base64.StdEncoding.DecodeString(block.Headers["Nonce"])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := envelopeCipher(passphrase, salt, params["N"], params["R"], params["P"])
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("incorrect length of nonce: %v", len(nonce))
+	}
+	blockType := strings.TrimPrefix(block.Type, encryptedPrefix)
+	plaintext, err := aead.Open(nil, nonce, block.Bytes, []byte(blockType))
+	if err != nil {
+		return nil, fmt.Errorf("incorrect passphrase or corrupt key file")
+	}
+	return &pem.Block{
+		Type:  blockType,
+		Bytes: plaintext,
+	}, nil
+}
+
+func envelopeCipher(passphrase []byte, salt []byte, n int, r int, p int) (cipher.AEAD, error) {
+	aesKey, err := scrypt.Key(passphrase, salt, n, r, p, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
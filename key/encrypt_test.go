@@ -0,0 +1,88 @@
+package key
+
+import (
+	"bytes"
+	"encoding/pem"
+	"os"
+	"testing"
+)
+
+func TestEncryptedIO(t *testing.T) {
+	for _, keyType := range []KeyType{Ed25519, Bls12381, Secp256k1, P256} {
+		k0, err := GenerateKey(keyType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kFile, err := os.CreateTemp(".", "tmp-*.pem")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(kFile.Name())
+		kFile.Close()
+		err = WriteEncryptedPrivateKey(k0, kFile.Name(), []byte("correct horse"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(kFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("incorrect permissions for key file: %v", info.Mode().Perm())
+		}
+		_, err = ReadPrivateKey(kFile.Name())
+		if err == nil {
+			t.Error("encrypted key read without passphrase")
+		}
+		_, err = ReadPrivateKeyWithPassphrase(kFile.Name(), func() ([]byte, error) {
+			return []byte("battery staple"), nil
+		})
+		if err == nil {
+			t.Error("encrypted key read with incorrect passphrase")
+		}
+		k1, err := ReadPrivateKeyWithPassphrase(kFile.Name(), func() ([]byte, error) {
+			return []byte("correct horse"), nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k1.keyType() != keyType || !bytes.Equal(k0.PrivateBytes(), k1.PrivateBytes()) {
+			t.Errorf("private key does not match for key type %v", keyType)
+		}
+	}
+}
+
+func TestEncryptedIO_ScryptLimits(t *testing.T) {
+	k, err := GenerateKey(Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kFile, err := os.CreateTemp(".", "tmp-*.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(kFile.Name())
+	kFile.Close()
+	err = WriteEncryptedPrivateKey(k, kFile.Name(), []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes, err := os.ReadFile(kFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]string{"N": "1073741824", "R": "1024", "P": "0"} {
+		block, _ := pem.Decode(pemBytes)
+		block.Headers[name] = value
+		err = os.WriteFile(kFile.Name(), pem.EncodeToMemory(block), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = ReadPrivateKeyWithPassphrase(kFile.Name(), func() ([]byte, error) {
+			return []byte("correct horse"), nil
+		})
+		if err == nil {
+			t.Errorf("encrypted key read with scrypt parameter %v = %v", name, value)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package key
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Hardened indices in derivation paths are offset by 2^31, per BIP-32.
+const hardenedOffset uint32 = 0x80000000
+
+// GenerateMnemonic creates a 24-word BIP-39 mnemonic for backing up a seed.
+func GenerateMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// SeedFromMnemonic validates a BIP-39 mnemonic and computes its seed.
+func SeedFromMnemonic(mnemonic string, passphrase string) ([]byte, error) {
+	return bip39.NewSeedWithErrorChecking(strings.Join(strings.Fields(mnemonic), " "), passphrase)
+}
+
+// DefaultPath is the derivation path used for keys generated from a new mnemonic.
+func DefaultPath(keyType KeyType) string {
+	switch keyType {
+	case Ed25519:
+		return "m/0'"
+	default:
+		return "m/0"
+	}
+}
+
+// DeriveKey deterministically derives a key from a seed, using SLIP-0010 for Ed25519
+// keys and EIP-2333 for BLS12-381 keys.
+func DeriveKey(keyType KeyType, seed []byte, path string) (Key, error) {
+	indices, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	switch keyType {
+	case Ed25519:
+		return deriveEd25519(seed, indices)
+	case Bls12381:
+		return deriveBls12381(seed, indices)
+	default:
+		return nil, fmt.Errorf("hierarchical derivation is not supported for key type: %v", keyType)
+	}
+}
+
+func parsePath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("derivation path must start with \"m\": %v", path)
+	}
+	var indices []uint32
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "H")
+		if hardened {
+			part = part[:len(part)-1]
+		}
+		index, err := strconv.ParseUint(part, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index in derivation path: %v", part)
+		}
+		if hardened {
+			index += uint64(hardenedOffset)
+		}
+		indices = append(indices, uint32(index))
+	}
+	return indices, nil
+}
+
+// See <https://github.com/satoshilabs/slips/blob/master/slip-0010.md>.
+func deriveEd25519(seed []byte, indices []uint32) (*KeyEd25519, error) {
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	node := mac.Sum(nil)
+	for _, index := range indices {
+		if index < hardenedOffset {
+			return nil, fmt.Errorf("Ed25519 derivation only supports hardened indices")
+		}
+		data := make([]byte, 37)
+		copy(data[1:33], node[:32])
+		binary.BigEndian.PutUint32(data[33:], index)
+		mac := hmac.New(sha512.New, node[32:])
+		mac.Write(data)
+		node = mac.Sum(nil)
+	}
+	return makeEd25519(ed25519.NewKeyFromSeed(node[:32]))
+}
+
+// See <https://eips.ethereum.org/EIPS/eip-2333>.
+func deriveBls12381(seed []byte, indices []uint32) (*KeyBls12381, error) {
+	if len(seed) < 32 {
+		return nil, fmt.Errorf("seed must be at least 32 bytes")
+	}
+	sk, err := hkdfModR(seed, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indices {
+		lamport, err := parentToLamportPK(sk, index)
+		if err != nil {
+			return nil, err
+		}
+		sk, err = hkdfModR(lamport, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return makeBls12381(bls12381.NewFr().FromBytes(sk.FillBytes(make([]byte, 32))))
+}
+
+func hkdfModR(ikm []byte, keyInfo []byte) (*big.Int, error) {
+	order := bls12381.NewG1().Q()
+	salt := []byte("BLS-SIG-KEYGEN-SALT-")
+	sk := new(big.Int)
+	for sk.Sign() == 0 {
+		hash := sha256.Sum256(salt)
+		salt = hash[:]
+		okm := make([]byte, 48)
+		info := append(append([]byte{}, keyInfo...), 0, 48)
+		reader := hkdf.New(sha256.New, append(append([]byte{}, ikm...), 0), salt, info)
+		_, err := io.ReadFull(reader, okm)
+		if err != nil {
+			return nil, err
+		}
+		sk.Mod(new(big.Int).SetBytes(okm), order)
+	}
+	return sk, nil
+}
+
+func ikmToLamportSK(ikm []byte, salt []byte) ([][]byte, error) {
+	okm := make([]byte, 32*255)
+	_, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, nil), okm)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([][]byte, 255)
+	for i := range chunks {
+		chunks[i] = okm[32*i : 32*(i+1)]
+	}
+	return chunks, nil
+}
+
+func parentToLamportPK(parent *big.Int, index uint32) ([]byte, error) {
+	salt := binary.BigEndian.AppendUint32(nil, index)
+	ikm := parent.FillBytes(make([]byte, 32))
+	notIkm := make([]byte, 32)
+	for i, b := range ikm {
+		notIkm[i] = ^b
+	}
+	lamport0, err := ikmToLamportSK(ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	lamport1, err := ikmToLamportSK(notIkm, salt)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	for _, chunk := range append(lamport0, lamport1...) {
+		digest := sha256.Sum256(chunk)
+		hash.Write(digest[:])
+	}
+	return hash.Sum(nil), nil
+}
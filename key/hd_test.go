@@ -0,0 +1,81 @@
+package key
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestDeriveKey_Ed25519(t *testing.T) {
+	// Test vector 1 from SLIP-0010.
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	for path, expected := range map[string]string{
+		"m":          "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+		"m/0'":       "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+		"m/0'/1'":    "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+		"m/0H/1H/2H": "92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9",
+	} {
+		k, err := DeriveKey(Ed25519, seed, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seed := k.(*KeyEd25519).Private.Seed()
+		if hex.EncodeToString(seed) != expected {
+			t.Errorf("incorrect key derived for %v: %x", path, seed)
+		}
+	}
+	_, err := DeriveKey(Ed25519, seed, "m/0")
+	if err == nil {
+		t.Error("derived Ed25519 key with non-hardened index")
+	}
+}
+
+func TestDeriveKey_Bls12381(t *testing.T) {
+	// Test case 0 from EIP-2333.
+	seed, _ := hex.DecodeString("c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04")
+	for path, expected := range map[string]string{
+		"m":   "6083874454709270928345386274498605044986640685124978867557563392430687146096",
+		"m/0": "20397789859736650942317412262472558107875392172444076792671091975210932703118",
+	} {
+		k, err := DeriveKey(Bls12381, seed, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sk := new(big.Int).SetBytes(k.PrivateBytes())
+		if sk.String() != expected {
+			t.Errorf("incorrect key derived for %v: %v", path, sk)
+		}
+	}
+}
+
+func TestMnemonic(t *testing.T) {
+	mnemonic, err := GenerateMnemonic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed0, err := SeedFromMnemonic(mnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed1, err := SeedFromMnemonic("  "+mnemonic+"\n", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, keyType := range []KeyType{Ed25519, Bls12381} {
+		k0, err := DeriveKey(keyType, seed0, DefaultPath(keyType))
+		if err != nil {
+			t.Fatal(err)
+		}
+		k1, err := DeriveKey(keyType, seed1, DefaultPath(keyType))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if Did(k0) != Did(k1) {
+			t.Errorf("derivation is not deterministic for key type %v", keyType)
+		}
+	}
+	_, err = SeedFromMnemonic(mnemonic+" abandon", "")
+	if err == nil {
+		t.Error("invalid mnemonic accepted")
+	}
+}
@@ -0,0 +1,188 @@
+package key
+
+import (
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// A JSON Web Key, per RFC 7517 and RFC 8037, with BLS12-381 keys represented as
+// octet key pairs on the "Bls12381G2" and "Bls12381G1" curves.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+	D   string `json:"d,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+func encodeJWK(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJWK(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+func publicJWK(keyType KeyType, pubBytes []byte) (*jsonWebKey, error) {
+	switch keyType {
+	case Ed25519:
+		return &jsonWebKey{Kty: "OKP", Crv: "Ed25519", X: encodeJWK(pubBytes)}, nil
+	case Bls12381:
+		return &jsonWebKey{Kty: "OKP", Crv: "Bls12381G2", X: encodeJWK(pubBytes)}, nil
+	case Bls12381G1:
+		return &jsonWebKey{Kty: "OKP", Crv: "Bls12381G1", X: encodeJWK(pubBytes)}, nil
+	case Secp256k1:
+		pub, err := secp256k1.ParsePubKey(pubBytes)
+		if err != nil {
+			return nil, err
+		}
+		uncompressed := pub.SerializeUncompressed()
+		return &jsonWebKey{Kty: "EC", Crv: "secp256k1", X: encodeJWK(uncompressed[1:33]), Y: encodeJWK(uncompressed[33:])}, nil
+	case P256:
+		pub, err := publicFromBytesP256(pubBytes)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonWebKey{Kty: "EC", Crv: "P-256", X: encodeJWK(pub.X.FillBytes(make([]byte, 32))), Y: encodeJWK(pub.Y.FillBytes(make([]byte, 32)))}, nil
+	default:
+		return nil, fmt.Errorf("invalid key type: %v", keyType)
+	}
+}
+
+// MarshalJWK serializes a key as a JWK whose key ID is its DID, omitting the private key unless requested.
+func MarshalJWK(k Key, private bool) ([]byte, error) {
+	j, err := publicJWK(k.keyType(), k.PublicBytes())
+	if err != nil {
+		return nil, err
+	}
+	j.Kid = Did(k)
+	if private {
+		if k1, okay := k.(*KeyEd25519); okay {
+			j.D = encodeJWK(k1.Private.Seed())
+		} else if _, okay := k.(*KeyEd25519Extended); okay {
+			return nil, fmt.Errorf("extended Ed25519 keys have no seed for a private JWK")
+		} else {
+			j.D = encodeJWK(k.PrivateBytes())
+		}
+	}
+	return json.MarshalIndent(j, "", "  ")
+}
+
+// MarshalPublicJWK serializes the public key of a DID as a JWK.
+func MarshalPublicJWK(did string) ([]byte, error) {
+	keyType, pubBytes, err := PublicKeyFromDid(did)
+	if err != nil {
+		return nil, err
+	}
+	j, err := publicJWK(keyType, pubBytes)
+	if err != nil {
+		return nil, err
+	}
+	j.Kid = did
+	return json.MarshalIndent(j, "", "  ")
+}
+
+// UnmarshalJWK parses a JWK, returning its DID and, if the JWK contains a private key, the key itself.
+func UnmarshalJWK(data []byte) (Key, string, error) {
+	var j jsonWebKey
+	err := json.Unmarshal(data, &j)
+	if err != nil {
+		return nil, "", err
+	}
+	keyType, pubBytes, err := j.public()
+	if err != nil {
+		return nil, "", err
+	}
+	did := didFromPublic(keyType, pubBytes)
+	if j.Kid != "" && j.Kid != did {
+		return nil, "", fmt.Errorf("JWK key ID %v does not match %v", j.Kid, did)
+	}
+	if j.D == "" {
+		return nil, did, nil
+	}
+	priBytes, err := decodeJWK(j.D)
+	if err != nil {
+		return nil, "", err
+	}
+	var k Key
+	switch keyType {
+	case Ed25519:
+		if len(priBytes) != ed25519.SeedSize {
+			return nil, "", fmt.Errorf("incorrect length of Ed25519 seed: %v", len(priBytes))
+		}
+		k, err = makeEd25519(ed25519.NewKeyFromSeed(priBytes))
+	case Bls12381:
+		k, err = fromBytesBls12381(priBytes)
+	case Secp256k1:
+		k, err = fromBytesSecp256k1(priBytes)
+	case P256:
+		k, err = fromBytesP256(priBytes)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if Did(k) != did {
+		return nil, "", fmt.Errorf("JWK private key does not match public key")
+	}
+	return k, did, nil
+}
+
+func (j *jsonWebKey) public() (KeyType, []byte, error) {
+	x, err := decodeJWK(j.X)
+	if err != nil {
+		return UnknownKeyType, nil, err
+	}
+	switch j.Kty + " " + j.Crv {
+	case "OKP Ed25519":
+		if len(x) != ed25519.PublicKeySize {
+			return UnknownKeyType, nil, fmt.Errorf("incorrect length of Ed25519 public key: %v", len(x))
+		}
+		return Ed25519, x, nil
+	case "OKP Bls12381G2":
+		_, err := pointG2FromBytesBls12381(x)
+		if err != nil {
+			return UnknownKeyType, nil, err
+		}
+		return Bls12381, x, nil
+	case "OKP Bls12381G1":
+		_, err := pointG1FromBytesBls12381(x)
+		if err != nil {
+			return UnknownKeyType, nil, err
+		}
+		return Bls12381G1, x, nil
+	case "EC secp256k1":
+		y, err := decodeJWK(j.Y)
+		if err != nil {
+			return UnknownKeyType, nil, err
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return UnknownKeyType, nil, fmt.Errorf("incorrect length of secp256k1 coordinates")
+		}
+		pub, err := secp256k1.ParsePubKey(append(append([]byte{0x04}, x...), y...))
+		if err != nil {
+			return UnknownKeyType, nil, err
+		}
+		return Secp256k1, pub.SerializeCompressed(), nil
+	case "EC P-256":
+		y, err := decodeJWK(j.Y)
+		if err != nil {
+			return UnknownKeyType, nil, err
+		}
+		curve := elliptic.P256()
+		bx := new(big.Int).SetBytes(x)
+		by := new(big.Int).SetBytes(y)
+		if len(x) != 32 || len(y) != 32 || !curve.IsOnCurve(bx, by) {
+			return UnknownKeyType, nil, fmt.Errorf("invalid P-256 public key")
+		}
+		return P256, elliptic.MarshalCompressed(curve, bx, by), nil
+	default:
+		return UnknownKeyType, nil, fmt.Errorf("unsupported JWK key type and curve: %v %v", j.Kty, j.Crv)
+	}
+}
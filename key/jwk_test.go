@@ -0,0 +1,61 @@
+package key
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v3/jwk"
+)
+
+func TestJWK(t *testing.T) {
+	for _, keyType := range []KeyType{Ed25519, Bls12381, Secp256k1, P256} {
+		k0, err := GenerateKey(keyType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d0 := Did(k0)
+		private, err := MarshalJWK(k0, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k1, d1, err := UnmarshalJWK(private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d1 != d0 || !bytes.Equal(k0.PrivateBytes(), k1.PrivateBytes()) {
+			t.Errorf("private JWK does not match for key type %v", keyType)
+		}
+		public, err := MarshalPublicJWK(d0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k2, d2, err := UnmarshalJWK(public)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k2 != nil || d2 != d0 {
+			t.Errorf("public JWK does not match for key type %v", keyType)
+		}
+	}
+}
+
+func TestJWK_Interoperability(t *testing.T) {
+	for _, keyType := range []KeyType{Ed25519, P256} {
+		k0, err := GenerateKey(keyType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		private, err := MarshalJWK(k0, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := jwk.ParseKey(private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kid, okay := parsed.KeyID()
+		if !okay || kid != Did(k0) {
+			t.Errorf("incorrect key ID: %v", kid)
+		}
+	}
+}
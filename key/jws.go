@@ -0,0 +1,129 @@
+package key
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// The protected header of a detached JWS. A non-empty signature context is carried in the
+// critical "ctx" parameter, so that verifiers unaware of contexts reject the signature.
+type jwsHeader struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid"`
+	Ctx  string   `json:"ctx,omitempty"`
+	Crit []string `json:"crit,omitempty"`
+}
+
+func jwsAlgorithm(keyType KeyType) (string, error) {
+	switch keyType {
+	case Ed25519:
+		return "EdDSA", nil
+	case Bls12381:
+		return "BLS12381G1", nil
+	case Secp256k1:
+		return "ES256K", nil
+	case P256:
+		return "ES256", nil
+	default:
+		return "", fmt.Errorf("invalid key type: %v", keyType)
+	}
+}
+
+// SignDetachedJWS signs the payload as a JWS in compact serialization with the payload
+// omitted, per RFC 7515 Appendix F. The key identifier is the DID of the key.
+func SignDetachedJWS(k Key, payload []byte, context string) (string, error) {
+	alg, err := jwsAlgorithm(k.keyType())
+	if err != nil {
+		return "", err
+	}
+	header := jwsHeader{
+		Alg: alg,
+		Kid: Did(k),
+	}
+	if context != "" {
+		header.Ctx = context
+		header.Crit = []string{"ctx"}
+	}
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	protected := base64.RawURLEncoding.EncodeToString(headerBytes)
+	input := jwsSigningInput(protected, payload)
+	var sig []byte
+	if ek, okay := k.(ecdsaKey); okay && context == "" {
+		digest := sha256.Sum256(input)
+		sig, err = ek.signDigest(digest[:])
+	} else {
+		sig, err = k.Sign(input, context)
+	}
+	if err != nil {
+		return "", err
+	}
+	return protected + ".." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// VerifyDetachedJWS verifies a detached JWS over the payload, returning the DID of the signer.
+// If the DID is not empty, the signer must match it.
+func VerifyDetachedJWS(did string, jws string, payload []byte) (string, error) {
+	parts := strings.Split(strings.TrimSpace(jws), ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("JWS does not have three parts")
+	}
+	if parts[1] != "" {
+		return "", fmt.Errorf("JWS payload is not detached")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", err
+	}
+	var header jwsHeader
+	err = json.Unmarshal(headerBytes, &header)
+	if err != nil {
+		return "", err
+	}
+	for _, crit := range header.Crit {
+		if crit != "ctx" {
+			return "", fmt.Errorf("unsupported critical JWS header parameter: %v", crit)
+		}
+	}
+	if header.Ctx != "" && len(header.Crit) == 0 {
+		return "", fmt.Errorf("JWS signature context is not marked critical")
+	}
+	if did != "" && header.Kid != did {
+		return "", fmt.Errorf("JWS was signed by %v instead of %v", header.Kid, did)
+	}
+	keyType, pubBytes, err := PublicKeyFromDid(header.Kid)
+	if err != nil {
+		return "", err
+	}
+	alg, err := jwsAlgorithm(keyType)
+	if err != nil {
+		return "", err
+	}
+	if header.Alg != alg {
+		return "", fmt.Errorf("JWS algorithm %v does not match key: %v", header.Alg, header.Kid)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", err
+	}
+	input := jwsSigningInput(parts[0], payload)
+	if header.Ctx == "" && (keyType == Secp256k1 || keyType == P256) {
+		digest := sha256.Sum256(input)
+		err = verifyDigestEcdsa(keyType, pubBytes, sig, digest[:])
+	} else {
+		err = Verify(header.Kid, sig, input, header.Ctx)
+	}
+	if err != nil {
+		return "", err
+	}
+	return header.Kid, nil
+}
+
+func jwsSigningInput(protected string, payload []byte) []byte {
+	return []byte(protected + "." + base64.RawURLEncoding.EncodeToString(payload))
+}
@@ -0,0 +1,62 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jws"
+)
+
+func TestDetachedJWS(t *testing.T) {
+	payload := []byte("release artifact")
+	for _, keyType := range []KeyType{Ed25519, Bls12381, Secp256k1, P256} {
+		for _, context := range []string{"", "nacatgunma-release"} {
+			k, err := GenerateKey(keyType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signature, err := SignDetachedJWS(k, payload, context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			did, err := VerifyDetachedJWS(Did(k), signature, payload)
+			if err != nil || did != Did(k) {
+				t.Errorf("detached JWS failed verification for key type %v: %v", keyType, err)
+			}
+			if _, err := VerifyDetachedJWS("", signature, []byte("tampered")); err == nil {
+				t.Errorf("tampered payload verified for key type %v", keyType)
+			}
+		}
+	}
+}
+
+func TestDetachedJWS_Interoperability(t *testing.T) {
+	payload := []byte("release artifact")
+	algorithms := map[KeyType]jwa.SignatureAlgorithm{
+		Ed25519: jwa.EdDSA(),
+		P256:    jwa.ES256(),
+	}
+	for keyType, alg := range algorithms {
+		k, err := GenerateKey(keyType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature, err := SignDetachedJWS(k, payload, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		public, err := MarshalPublicJWK(Did(k))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := jwk.ParseKey(public)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = jws.Verify([]byte(signature), jws.WithKey(alg, parsed), jws.WithDetachedPayload(payload))
+		if err != nil {
+			t.Errorf("detached JWS rejected by jwx for key type %v: %v", keyType, err)
+		}
+	}
+}
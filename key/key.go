@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/multiformats/go-multibase"
 	"github.com/trustbloc/did-go/doc/did"
@@ -26,6 +27,10 @@ const (
 	UnknownKeyType KeyType = iota
 	Ed25519
 	Bls12381
+	Secp256k1
+	P256
+	// BLS12-381 G1 public keys, such as those of TGDH groups, are only for key agreement.
+	Bls12381G1
 )
 
 func prefixBytes(keyType KeyType) []byte {
@@ -35,6 +40,12 @@ func prefixBytes(keyType KeyType) []byte {
 		return []byte{0xED, 0x01}
 	case Bls12381:
 		return []byte{0xEB, 0x01}
+	case Secp256k1:
+		return []byte{0xE7, 0x01}
+	case P256:
+		return []byte{0x80, 0x24}
+	case Bls12381G1:
+		return []byte{0xEA, 0x01}
 	default:
 		panic(fmt.Errorf("invalid key type: %v", keyType))
 	}
@@ -46,27 +57,57 @@ func blockType(keyType KeyType) string {
 		return "ED25519 PRIVATE KEY"
 	case Bls12381:
 		return "BLS12-381 PRIVATE KEY"
+	case Secp256k1:
+		return "SECP256K1 PRIVATE KEY"
+	case P256:
+		return "EC PRIVATE KEY"
 	default:
 		panic(fmt.Errorf("invalid key type: %v", keyType))
 	}
 }
 
+// Extended Ed25519 keys share the key type of Ed25519 but not its PEM encoding.
+const blockTypeEd25519Extended = "ED25519 EXTENDED PRIVATE KEY"
+
 func GenerateKey(keyType KeyType) (Key, error) {
 	switch keyType {
 	case Ed25519:
 		return generateEd25519()
 	case Bls12381:
 		return generateBls12381()
+	case Secp256k1:
+		return generateSecp256k1()
+	case P256:
+		return generateP256()
 	default:
 		return nil, fmt.Errorf("invalid key type: %v", keyType)
 	}
 }
 
+// Verify checks a signature by a did:key, or by any DID that the default registry can
+// resolve, in which case the signature must verify under one of the selected keys.
 func Verify(did string, sigBytes []byte, message []byte, context string) error {
+	if !strings.HasPrefix(did, "did:key:") {
+		keys, err := DefaultRegistry.VerificationKeys(did)
+		if err != nil {
+			return err
+		}
+		for _, vk := range keys {
+			err = verifyPublic(vk.Type, vk.Public, sigBytes, message, context)
+			if err == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("signature verification failed for %v: %w", did, err)
+	}
 	keyType, pubBytes, err := PublicKeyFromDid(did)
 	if err != nil {
 		return err
 	}
+	return verifyPublic(keyType, pubBytes, sigBytes, message, context)
+}
+
+func verifyPublic(keyType KeyType, pubBytes []byte, sigBytes []byte, message []byte, context string) error {
 	switch keyType {
 	case Ed25519:
 		{
@@ -84,21 +125,73 @@ func Verify(did string, sigBytes []byte, message []byte, context string) error {
 			}
 			return verifyBls12381(pub, sig, message, context)
 		}
+	case Secp256k1:
+		{
+			return verifySecp256k1(pubBytes, sigBytes, message, context)
+		}
+	case P256:
+		{
+			pub, err := publicFromBytesP256(pubBytes)
+			if err != nil {
+				return err
+			}
+			return verifyP256(pub, sigBytes, message, context)
+		}
 	}
 	return fmt.Errorf("invalid key type: %v", keyType)
 }
 
 func ReadPrivateKey(filename string) (Key, error) {
+	return ReadPrivateKeyWithPassphrase(filename, nil)
+}
+
+// ReadPrivateKeyWithPassphrase reads a PEM private key file or a Cardano text envelope,
+// calling the passphrase function only if the file is encrypted.
+func ReadPrivateKeyWithPassphrase(filename string, passphrase func() ([]byte, error)) (Key, error) {
+	keyBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if isTextEnvelope(keyBytes) {
+		return decodeTextEnvelope(keyBytes)
+	}
+	block, err := decodePem(keyBytes, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return decodePrivateKey(block)
+}
+
+func readPemFile(filename string, passphrase func() ([]byte, error)) (*pem.Block, error) {
 	pemBytes, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
+	return decodePem(pemBytes, passphrase)
+}
+
+func decodePem(pemBytes []byte, passphrase func() ([]byte, error)) (*pem.Block, error) {
 	block, rest := pem.Decode(pemBytes)
 	if block == nil {
 		return nil, fmt.Errorf("no PEM data present")
 	} else if len(rest) > 0 {
 		return nil, fmt.Errorf("extra PEM data present")
-	} else if block.Type == blockType(Ed25519) {
+	}
+	if isEncrypted(block) {
+		if passphrase == nil {
+			return nil, fmt.Errorf("private key is encrypted")
+		}
+		secret, err := passphrase()
+		if err != nil {
+			return nil, err
+		}
+		return decryptBlock(block, secret)
+	}
+	return block, nil
+}
+
+func decodePrivateKey(block *pem.Block) (Key, error) {
+	if block.Type == blockType(Ed25519) {
 		pri, err := x509.ParsePKCS8PrivateKey(block.Bytes)
 		if err == nil {
 			keyEd25519, okay := pri.(ed25519.PrivateKey)
@@ -108,29 +201,80 @@ func ReadPrivateKey(filename string) (Key, error) {
 			return fromBytesEd25519(block.Bytes)
 		}
 		return fromBytesEd25519(block.Bytes)
+	} else if block.Type == blockTypeEd25519Extended {
+		return fromBytesEd25519Extended(block.Bytes)
 	} else if block.Type == blockType(Bls12381) {
 		return fromBytesBls12381(block.Bytes)
+	} else if block.Type == blockType(Secp256k1) {
+		return fromBytesSecp256k1(block.Bytes)
+	} else if block.Type == blockType(P256) {
+		pri, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return makeP256(pri)
 	}
 	return nil, fmt.Errorf("wrong PEM block type")
 }
 
-func WritePrivateKey(k Key, filename string) error {
+func encodePrivateKey(k Key) (*pem.Block, error) {
 	var bytes []byte
-	keyEd25519, okay := k.(*KeyEd25519)
-	if okay {
+	switch k1 := k.(type) {
+	case *KeyEd25519Extended:
+		return &pem.Block{
+			Type:  blockTypeEd25519Extended,
+			Bytes: k1.bytes(),
+		}, nil
+	case *KeyEd25519:
 		var err error
-		bytes, err = x509.MarshalPKCS8PrivateKey(keyEd25519.Private)
+		bytes, err = x509.MarshalPKCS8PrivateKey(k1.Private)
 		if err != nil {
 			bytes = k.PrivateBytes()
 		}
-	} else {
+	case *KeyP256:
+		var err error
+		bytes, err = x509.MarshalECPrivateKey(k1.Private)
+		if err != nil {
+			return nil, err
+		}
+	default:
 		bytes = k.PrivateBytes()
 	}
-	block := &pem.Block{
+	return &pem.Block{
 		Type:  blockType(k.keyType()),
 		Bytes: bytes,
+	}, nil
+}
+
+func WritePrivateKey(k Key, filename string) error {
+	block, err := encodePrivateKey(k)
+	if err != nil {
+		return err
+	}
+	return writePemFile(block, filename)
+}
+
+// WriteEncryptedPrivateKey writes a private key file encrypted with a key derived from the passphrase.
+func WriteEncryptedPrivateKey(k Key, filename string, passphrase []byte) error {
+	block, err := encodePrivateKey(k)
+	if err != nil {
+		return err
+	}
+	block, err = encryptBlock(block, passphrase)
+	if err != nil {
+		return err
+	}
+	return writePemFile(block, filename)
+}
+
+func writePemFile(block *pem.Block, filename string) error {
+	handle, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
 	}
-	handle, err := os.Create(filename)
+	defer handle.Close()
+	// Tighten the permissions of a pre-existing file, which OpenFile leaves unchanged.
+	err = handle.Chmod(0600)
 	if err != nil {
 		return err
 	}
@@ -142,7 +286,11 @@ func WritePrivateKey(k Key, filename string) error {
 }
 
 func Did(k Key) string {
-	prefixedKey := append(prefixBytes(k.keyType()), k.PublicBytes()...)
+	return didFromPublic(k.keyType(), k.PublicBytes())
+}
+
+func didFromPublic(keyType KeyType, pubBytes []byte) string {
+	prefixedKey := append(prefixBytes(keyType), pubBytes...)
 	str, err := multibase.Encode(multibase.Base58BTC, prefixedKey)
 	if err != nil {
 		panic(err)
@@ -150,8 +298,64 @@ func Did(k Key) string {
 	return "did:key:" + str
 }
 
-func ResolveDid(did string) (*did.DocResolution, error) {
-	return key.New().Read(did)
+func ResolveDid(didKey string) (*did.DocResolution, error) {
+	if !strings.HasPrefix(didKey, "did:key:") {
+		doc, err := DefaultRegistry.Resolve(didKey)
+		if err != nil {
+			return nil, err
+		}
+		return &did.DocResolution{
+			Context:     []string{"https://w3id.org/did-resolution/v1"},
+			DIDDocument: doc,
+		}, nil
+	}
+	keyType, pubBytes, err := PublicKeyFromDid(didKey)
+	if err == nil && keyType == Secp256k1 {
+		// The did-go key method does not support secp256k1.
+		return resolveSecp256k1(didKey, pubBytes), nil
+	}
+	if err == nil && keyType == Bls12381G1 {
+		// Nor does it support key agreement with BLS12-381 G1 keys.
+		return resolveBls12381G1(didKey, pubBytes), nil
+	}
+	return key.New().Read(didKey)
+}
+
+func resolveSecp256k1(didKey string, pubBytes []byte) *did.DocResolution {
+	keyID := didKey + "#" + strings.TrimPrefix(didKey, "did:key:")
+	method := did.NewVerificationMethodFromBytes(keyID, "EcdsaSecp256k1VerificationKey2019", didKey, pubBytes)
+	now := time.Now()
+	return &did.DocResolution{
+		Context: []string{"https://w3id.org/did-resolution/v1"},
+		DIDDocument: &did.Doc{
+			Context:              []string{"https://w3id.org/did/v1"},
+			ID:                   didKey,
+			VerificationMethod:   []did.VerificationMethod{*method},
+			Authentication:       []did.Verification{*did.NewReferencedVerification(method, did.Authentication)},
+			AssertionMethod:      []did.Verification{*did.NewReferencedVerification(method, did.AssertionMethod)},
+			CapabilityDelegation: []did.Verification{*did.NewReferencedVerification(method, did.CapabilityDelegation)},
+			CapabilityInvocation: []did.Verification{*did.NewReferencedVerification(method, did.CapabilityInvocation)},
+			Created:              &now,
+			Updated:              &now,
+		},
+	}
+}
+
+func resolveBls12381G1(didKey string, pubBytes []byte) *did.DocResolution {
+	keyID := didKey + "#" + strings.TrimPrefix(didKey, "did:key:")
+	method := did.NewVerificationMethodFromBytes(keyID, "Bls12381G1Key2020", didKey, pubBytes)
+	now := time.Now()
+	return &did.DocResolution{
+		Context: []string{"https://w3id.org/did-resolution/v1"},
+		DIDDocument: &did.Doc{
+			Context:            []string{"https://w3id.org/did/v1"},
+			ID:                 didKey,
+			VerificationMethod: []did.VerificationMethod{*method},
+			KeyAgreement:       []did.Verification{*did.NewReferencedVerification(method, did.KeyAgreement)},
+			Created:            &now,
+			Updated:            &now,
+		},
+	}
 }
 
 func PublicKeyFromDid(did string) (KeyType, []byte, error) {
@@ -163,6 +367,10 @@ func PublicKeyFromDid(did string) (KeyType, []byte, error) {
 	if err != nil {
 		return UnknownKeyType, nil, fmt.Errorf("multibase decode error: %v", err)
 	}
+	return publicKeyFromMulticodec(data)
+}
+
+func publicKeyFromMulticodec(data []byte) (KeyType, []byte, error) {
 	if len(data) < 2 {
 		return UnknownKeyType, nil, fmt.Errorf("invalid multicodec: %x", data)
 	}
@@ -175,5 +383,17 @@ func PublicKeyFromDid(did string) (KeyType, []byte, error) {
 	if firstTwoMatch(data, prefixBytes(Bls12381)) {
 		return Bls12381, data[2:], nil
 	}
+	if firstTwoMatch(data, prefixBytes(Secp256k1)) {
+		return Secp256k1, data[2:], nil
+	}
+	if firstTwoMatch(data, prefixBytes(P256)) {
+		return P256, data[2:], nil
+	}
+	if firstTwoMatch(data, prefixBytes(Bls12381G1)) {
+		if _, err := pointG1FromBytesBls12381(data[2:]); err != nil {
+			return UnknownKeyType, nil, err
+		}
+		return Bls12381G1, data[2:], nil
+	}
 	return UnknownKeyType, nil, fmt.Errorf("unsupported multicodec key: %x", data[:2])
 }
@@ -2,6 +2,7 @@ package key
 
 import (
 	"bytes"
+	"math/big"
 	"os"
 	"reflect"
 	"testing"
@@ -149,3 +150,49 @@ func genContext() gopter.Gen {
 func genString(minLen int, maxLen int) gopter.Gen {
 	return genBytes(minLen, maxLen).Map(func(v []uint8) string { return string(v) })
 }
+
+// testContextSeparation checks that a signature with an empty context, over a message
+// that embeds a length-prefixed context, does not verify under that context.
+func testLowS(keyType KeyType, n *big.Int, t *testing.T) {
+	k, err := GenerateKey(keyType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	did := Did(k)
+	msg := []byte("header payload")
+	for range 8 {
+		sig, err := k.Sign(msg, did)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := new(big.Int).SetBytes(sig[32:])
+		if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+			t.Error("signature has a high S")
+		}
+		flipped := append([]byte{}, sig[:32]...)
+		flipped = append(flipped, new(big.Int).Sub(n, s).FillBytes(make([]byte, 32))...)
+		if Verify(did, flipped, msg, did) == nil {
+			t.Error("signature with a high S verified")
+		}
+	}
+}
+
+func testContextSeparation(keyType KeyType, t *testing.T) {
+	k, err := GenerateKey(keyType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	did := Did(k)
+	msg := []byte("header payload")
+	forged := append(append([]byte{byte(len(did))}, did...), msg...)
+	sig, err := k.Sign(forged, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Verify(did, sig, forged, "") != nil {
+		t.Error("signature with empty context failed verification")
+	}
+	if Verify(did, sig, msg, did) == nil {
+		t.Error("signature with empty context verified under another context")
+	}
+}
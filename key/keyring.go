@@ -0,0 +1,169 @@
+package key
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	didpkg "github.com/trustbloc/did-go/doc/did"
+)
+
+// A Keyring is a directory that maps human-readable aliases to private key files or,
+// for the keys of other parties, to DIDs alone.
+type Keyring struct {
+	Dir     string `json:"-"`
+	Default string
+	Entries map[string]KeyringEntry
+}
+
+type KeyringEntry struct {
+	Did  string
+	File string `json:",omitempty"`
+}
+
+const keyringFile = "keyring.json"
+
+// OpenKeyring reads the keyring in the directory, which need not exist yet.
+func OpenKeyring(dir string) (*Keyring, error) {
+	keyring := &Keyring{
+		Dir:     dir,
+		Entries: make(map[string]KeyringEntry),
+	}
+	jsonBytes, err := os.ReadFile(filepath.Join(dir, keyringFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return keyring, nil
+	} else if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(jsonBytes, keyring)
+	if err != nil {
+		return nil, fmt.Errorf("malformed keyring: %w", err)
+	}
+	if keyring.Entries == nil {
+		keyring.Entries = make(map[string]KeyringEntry)
+	}
+	return keyring, nil
+}
+
+func (keyring *Keyring) Save() error {
+	err := os.MkdirAll(keyring.Dir, 0700)
+	if err != nil {
+		return err
+	}
+	jsonBytes, err := json.MarshalIndent(keyring, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(keyring.Dir, keyringFile), jsonBytes, 0600)
+}
+
+// Add records an alias for a DID and, if the filename is not empty, its private key file.
+// Relative filenames are resolved against the keyring directory.
+func (keyring *Keyring) Add(alias string, did string, filename string) error {
+	if alias == "" || strings.HasPrefix(alias, "did:") || strings.ContainsAny(alias, " \t\n") {
+		return fmt.Errorf("invalid alias: %q", alias)
+	}
+	if _, okay := keyring.Entries[alias]; okay {
+		return fmt.Errorf("alias already in keyring: %v", alias)
+	}
+	if _, err := didpkg.Parse(did); err != nil {
+		return err
+	}
+	keyring.Entries[alias] = KeyringEntry{
+		Did:  did,
+		File: filename,
+	}
+	if keyring.Default == "" && filename != "" {
+		keyring.Default = alias
+	}
+	return nil
+}
+
+func (keyring *Keyring) Remove(alias string) error {
+	if _, okay := keyring.Entries[alias]; !okay {
+		return fmt.Errorf("alias not in keyring: %v", alias)
+	}
+	delete(keyring.Entries, alias)
+	if keyring.Default == alias {
+		keyring.Default = ""
+	}
+	return nil
+}
+
+func (keyring *Keyring) SetDefault(alias string) error {
+	entry, okay := keyring.Entries[alias]
+	if !okay {
+		return fmt.Errorf("alias not in keyring: %v", alias)
+	}
+	if entry.File == "" {
+		return fmt.Errorf("alias has no private key: %v", alias)
+	}
+	keyring.Default = alias
+	return nil
+}
+
+// Aliases lists the aliases in the keyring in sorted order.
+func (keyring *Keyring) Aliases() []string {
+	aliases := make([]string, 0, len(keyring.Entries))
+	for alias := range keyring.Entries {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// KeyFile returns the private key file for the alias, or for the default key if the alias is empty.
+func (keyring *Keyring) KeyFile(alias string) (string, error) {
+	if alias == "" {
+		if keyring.Default == "" {
+			return "", fmt.Errorf("keyring has no default key")
+		}
+		alias = keyring.Default
+	}
+	entry, okay := keyring.Entries[alias]
+	if !okay {
+		return "", fmt.Errorf("alias not in keyring: %v", alias)
+	}
+	if entry.File == "" {
+		return "", fmt.Errorf("alias has no private key: %v", alias)
+	}
+	if filepath.IsAbs(entry.File) {
+		return entry.File, nil
+	}
+	return filepath.Join(keyring.Dir, entry.File), nil
+}
+
+// ResolveAlias returns the DID for an alias, passing DIDs through unchanged.
+func (keyring *Keyring) ResolveAlias(aliasOrDid string) (string, error) {
+	if strings.HasPrefix(aliasOrDid, "did:") {
+		return aliasOrDid, nil
+	}
+	entry, okay := keyring.Entries[aliasOrDid]
+	if !okay {
+		return "", fmt.Errorf("alias not in keyring: %v", aliasOrDid)
+	}
+	return entry.Did, nil
+}
+
+// Alias returns the first alias, in sorted order, for a DID.
+func (keyring *Keyring) Alias(did string) (string, bool) {
+	for _, alias := range keyring.Aliases() {
+		if keyring.Entries[alias].Did == did {
+			return alias, true
+		}
+	}
+	return "", false
+}
+
+// Display returns the alias for a DID if there is one, and otherwise the DID itself.
+func (keyring *Keyring) Display(did string) string {
+	if alias, okay := keyring.Alias(did); okay {
+		return alias
+	}
+	return did
+}
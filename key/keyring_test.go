@@ -0,0 +1,58 @@
+package key
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyring(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keyring")
+	keyring, err := OpenKeyring(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := GenerateKey(Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WritePrivateKey(alice, dir+"-alice.pem"); err != nil {
+		t.Fatal(err)
+	}
+	bob, err := GenerateKey(Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.Add("alice", Did(alice), dir+"-alice.pem"); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.Add("bob", Did(bob), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.Add("did:key:z", Did(bob), ""); err == nil {
+		t.Error("DID accepted as alias")
+	}
+	if err := keyring.SetDefault("bob"); err == nil {
+		t.Error("public key accepted as default")
+	}
+	if err := keyring.Save(); err != nil {
+		t.Fatal(err)
+	}
+	keyring, err = OpenKeyring(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename, err := keyring.KeyFile("")
+	if err != nil || filename != dir+"-alice.pem" {
+		t.Errorf("incorrect default key file: %v %v", filename, err)
+	}
+	did, err := keyring.ResolveAlias("bob")
+	if err != nil || did != Did(bob) {
+		t.Errorf("incorrect DID for alias: %v %v", did, err)
+	}
+	if keyring.Display(Did(alice)) != "alice" || keyring.Display("did:key:z") != "did:key:z" {
+		t.Error("incorrect display of DIDs")
+	}
+	if err := keyring.Remove("alice"); err != nil || keyring.Default != "" {
+		t.Error("removing the default key failed")
+	}
+}
@@ -0,0 +1,110 @@
+package key
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+type KeyP256 struct {
+	Private *ecdsa.PrivateKey
+}
+
+func generateP256() (*KeyP256, error) {
+	pri, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return makeP256(pri)
+}
+
+func makeP256(pri *ecdsa.PrivateKey) (*KeyP256, error) {
+	if pri.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("incorrect curve for P-256 private key: %v", pri.Curve.Params().Name)
+	}
+	return &KeyP256{
+		Private: pri,
+	}, nil
+}
+
+func fromBytesP256(priBytes []byte) (*KeyP256, error) {
+	if len(priBytes) != 32 {
+		return nil, fmt.Errorf("incorrect length of P-256 private key: %v", len(priBytes))
+	}
+	curve := elliptic.P256()
+	d := new(big.Int).SetBytes(priBytes)
+	if d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, fmt.Errorf("invalid P-256 private key")
+	}
+	pri := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: curve},
+		D:         d,
+	}
+	pri.PublicKey.X, pri.PublicKey.Y = curve.ScalarBaseMult(priBytes)
+	return makeP256(pri)
+}
+
+func (k *KeyP256) keyType() KeyType {
+	return P256
+}
+
+func (k *KeyP256) PrivateBytes() []byte {
+	return k.Private.D.FillBytes(make([]byte, 32))
+}
+
+func (k *KeyP256) PublicBytes() []byte {
+	return elliptic.MarshalCompressed(elliptic.P256(), k.Private.X, k.Private.Y)
+}
+
+func (k *KeyP256) Sign(message []byte, context string) ([]byte, error) {
+	digest, err := digestEcdsa(message, context)
+	if err != nil {
+		return nil, err
+	}
+	return k.signDigest(digest)
+}
+
+func (k *KeyP256) signDigest(digest []byte) ([]byte, error) {
+	r, s, err := ecdsa.Sign(rand.Reader, k.Private, digest)
+	if err != nil {
+		return nil, err
+	}
+	n := elliptic.P256().Params().N
+	if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		s.Sub(n, s)
+	}
+	return encodeEcdsa(r, s), nil
+}
+
+func publicFromBytesP256(pubBytes []byte) (*ecdsa.PublicKey, error) {
+	curve := elliptic.P256()
+	x, y := elliptic.UnmarshalCompressed(curve, pubBytes)
+	if x == nil {
+		return nil, fmt.Errorf("invalid P-256 public key")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func verifyP256(pub *ecdsa.PublicKey, sig []byte, message []byte, context string) error {
+	digest, err := digestEcdsa(message, context)
+	if err != nil {
+		return err
+	}
+	return verifyDigestP256(pub, sig, digest)
+}
+
+func verifyDigestP256(pub *ecdsa.PublicKey, sig []byte, digest []byte) error {
+	r, s, err := decodeEcdsa(sig)
+	if err != nil {
+		return err
+	}
+	if s.Cmp(new(big.Int).Rsh(elliptic.P256().Params().N, 1)) > 0 {
+		return fmt.Errorf("ECDSA signature has a high S")
+	}
+	if !ecdsa.Verify(pub, digest, r, s) {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package key
+
+import (
+	"crypto/elliptic"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateKey_P256(t *testing.T) {
+	testGenerateKey(P256, reflect.TypeOf(&KeyP256{}), t)
+}
+
+func TestDid_P256(t *testing.T) {
+	testDid(P256, "did:key:zDn", t)
+}
+
+func TestPubFromDid_P256(t *testing.T) {
+	testPubFromDid(P256, t)
+}
+
+func TestDidResolution_P256(t *testing.T) {
+	testDidResolution(P256, "JsonWebKey2020", t)
+}
+
+func TestIO_P256(t *testing.T) {
+	testIO(
+		P256,
+		func(kx Key, ky Key) {
+			kx1, ok := kx.(*KeyP256)
+			if !ok {
+				t.Errorf("incorrect key type generated: %T", kx1)
+			}
+			ky1, ok := ky.(*KeyP256)
+			if !ok {
+				t.Errorf("incorrect key type read: %T", ky1)
+			}
+			if !kx1.Private.Equal(ky1.Private) {
+				t.Error("private key does not match")
+			}
+		},
+		t,
+	)
+}
+
+func TestVerify_P256(t *testing.T) {
+	testVerify(P256, t)
+}
+
+func TestContextSeparation_P256(t *testing.T) {
+	testContextSeparation(P256, t)
+}
+
+func TestLowS_P256(t *testing.T) {
+	testLowS(P256, elliptic.P256().Params().N, t)
+}
@@ -0,0 +1,329 @@
+package key
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/multiformats/go-multibase"
+	"github.com/trustbloc/did-go/doc/did"
+	"github.com/trustbloc/did-go/method/web"
+	vdrapi "github.com/trustbloc/did-go/vdr/api"
+	"github.com/trustbloc/kms-go/doc/jose/jwk"
+)
+
+// A MethodResolver resolves the DIDs of one DID method to their documents.
+type MethodResolver interface {
+	Resolve(did string) (*did.Doc, error)
+}
+
+// A Registry dispatches DID resolution by method, preferring pinned documents and
+// caching the documents that it resolves.
+type Registry struct {
+	methods map[string]MethodResolver
+	pinned  map[string]*did.Doc
+	cache   map[string]*did.Doc
+}
+
+// How long a did:web resolver waits for a DID document.
+const WebTimeout = 10 * time.Second
+
+// A VerificationKey is a public key selected from a DID document.
+type VerificationKey struct {
+	ID     string
+	Type   KeyType
+	Public []byte
+}
+
+// NewRegistry creates a registry for the did:key, did:web, and did:peer methods.
+func NewRegistry() *Registry {
+	registry := &Registry{
+		methods: make(map[string]MethodResolver),
+		pinned:  make(map[string]*did.Doc),
+		cache:   make(map[string]*did.Doc),
+	}
+	registry.Register("key", keyResolver{})
+	registry.Register("web", NewWebResolver())
+	registry.Register("peer", peerResolver{})
+	return registry
+}
+
+// The registry used by Verify and ResolveDid.
+var DefaultRegistry = NewRegistry()
+
+// Register sets the resolver for a DID method, discarding the cached documents.
+func (registry *Registry) Register(method string, resolver MethodResolver) {
+	registry.methods[method] = resolver
+	registry.cache = make(map[string]*did.Doc)
+}
+
+// Disable makes resolution of a DID method fail unless the document is pinned.
+func (registry *Registry) Disable(method string) {
+	registry.Register(method, disabledResolver{method: method})
+}
+
+// Pin resolves the DID of a document to that document, regardless of its method.
+func (registry *Registry) Pin(doc *did.Doc) {
+	registry.pinned[doc.ID] = doc
+}
+
+// PinFile pins the DID document in a JSON file.
+func (registry *Registry) PinFile(filename string) error {
+	docBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	doc, err := did.ParseDocument(docBytes)
+	if err != nil {
+		return fmt.Errorf("invalid DID document %v: %w", filename, err)
+	}
+	registry.Pin(doc)
+	return nil
+}
+
+// Resolve resolves a DID, ignoring any fragment.
+func (registry *Registry) Resolve(didURL string) (*did.Doc, error) {
+	id, _, _ := strings.Cut(didURL, "#")
+	if doc, okay := registry.pinned[id]; okay {
+		return doc, nil
+	}
+	if doc, okay := registry.cache[id]; okay {
+		return doc, nil
+	}
+	parsed, err := did.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	resolver, okay := registry.methods[parsed.Method]
+	if !okay {
+		return nil, fmt.Errorf("unsupported DID method: %v", parsed.Method)
+	}
+	doc, err := resolver.Resolve(id)
+	if err != nil {
+		return nil, err
+	}
+	registry.cache[id] = doc
+	return doc, nil
+}
+
+// VerificationKeys resolves a DID and selects the assertion methods of the document, or
+// only the one named by the fragment of the DID URL.
+func (registry *Registry) VerificationKeys(didURL string) ([]VerificationKey, error) {
+	doc, err := registry.Resolve(didURL)
+	if err != nil {
+		return nil, err
+	}
+	_, fragment, hasFragment := strings.Cut(didURL, "#")
+	var methods []did.VerificationMethod
+	for _, v := range doc.AssertionMethod {
+		vm := v.VerificationMethod
+		if !hasFragment || vm.ID == didURL || vm.ID == "#"+fragment {
+			methods = append(methods, vm)
+		}
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("no assertion method %v", didURL)
+	}
+	var keys []VerificationKey
+	for _, vm := range methods {
+		keyType, pubBytes, err := publicKeyFromMethod(&vm)
+		if err != nil {
+			// Skip keys of unsupported types, which cannot have made our signatures.
+			continue
+		}
+		keys = append(keys, VerificationKey{
+			ID:     vm.ID,
+			Type:   keyType,
+			Public: pubBytes,
+		})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no supported verification methods for %v", didURL)
+	}
+	return keys, nil
+}
+
+func publicKeyFromMethod(vm *did.VerificationMethod) (KeyType, []byte, error) {
+	if publicJWK := vm.JSONWebKey(); publicJWK != nil {
+		jwkBytes, err := json.Marshal(publicJWK)
+		if err != nil {
+			return UnknownKeyType, nil, err
+		}
+		var j jsonWebKey
+		err = json.Unmarshal(jwkBytes, &j)
+		if err != nil {
+			return UnknownKeyType, nil, err
+		}
+		return j.public()
+	}
+	switch vm.Type {
+	case "Multikey", "Ed25519VerificationKey2020":
+		return publicKeyFromMulticodec(vm.Value)
+	case "Ed25519VerificationKey2018":
+		return Ed25519, vm.Value, nil
+	case "Bls12381G2Key2020":
+		return Bls12381, vm.Value, nil
+	case "Bls12381G1Key2020":
+		return Bls12381G1, vm.Value, nil
+	case "EcdsaSecp256k1VerificationKey2019":
+		return Secp256k1, vm.Value, nil
+	default:
+		return UnknownKeyType, nil, fmt.Errorf("unsupported verification method type: %v", vm.Type)
+	}
+}
+
+type keyResolver struct{}
+
+func (keyResolver) Resolve(didKey string) (*did.Doc, error) {
+	resolution, err := ResolveDid(didKey)
+	if err != nil {
+		return nil, err
+	}
+	return resolution.DIDDocument, nil
+}
+
+type disabledResolver struct {
+	method string
+}
+
+func (resolver disabledResolver) Resolve(id string) (*did.Doc, error) {
+	return nil, fmt.Errorf("resolution of did:%v is disabled, so %v must be pinned", resolver.method, id)
+}
+
+// A WebResolver fetches did:web documents over HTTPS with its client.
+type WebResolver struct {
+	Client *http.Client
+}
+
+// NewWebResolver creates a did:web resolver whose requests time out after WebTimeout.
+func NewWebResolver() *WebResolver {
+	return &WebResolver{Client: &http.Client{Timeout: WebTimeout}}
+}
+
+func (resolver *WebResolver) Resolve(didWeb string) (*did.Doc, error) {
+	resolution, err := web.New().Read(didWeb, vdrapi.WithOption(web.HTTPClientOpt, resolver.Client))
+	if err != nil {
+		return nil, err
+	}
+	return resolution.DIDDocument, nil
+}
+
+type peerResolver struct{}
+
+// Resolve supports numeric algorithm 0, which wraps a single key, and numeric algorithm 2,
+// which lists keys prefixed by their purpose.
+func (peerResolver) Resolve(didPeer string) (*did.Doc, error) {
+	suffix := strings.TrimPrefix(didPeer, "did:peer:")
+	if suffix == "" || suffix == didPeer {
+		return nil, fmt.Errorf("invalid did:peer: %v", didPeer)
+	}
+	doc := &did.Doc{
+		Context: []string{"https://www.w3.org/ns/did/v1"},
+		ID:      didPeer,
+	}
+	addKey := func(id string, encoded string, relationships ...did.VerificationRelationship) error {
+		_, data, err := multibase.Decode(encoded)
+		if err != nil {
+			return fmt.Errorf("multibase decode error: %v", err)
+		}
+		keyType, pubBytes, err := publicKeyFromMulticodec(data)
+		if err != nil {
+			return err
+		}
+		vm, err := verificationMethodFromKey(id, didPeer, keyType, pubBytes)
+		if err != nil {
+			return err
+		}
+		doc.VerificationMethod = append(doc.VerificationMethod, *vm)
+		for _, relationship := range relationships {
+			verification := did.NewReferencedVerification(vm, relationship)
+			switch relationship {
+			case did.Authentication:
+				doc.Authentication = append(doc.Authentication, *verification)
+			case did.AssertionMethod:
+				doc.AssertionMethod = append(doc.AssertionMethod, *verification)
+			case did.CapabilityDelegation:
+				doc.CapabilityDelegation = append(doc.CapabilityDelegation, *verification)
+			case did.CapabilityInvocation:
+				doc.CapabilityInvocation = append(doc.CapabilityInvocation, *verification)
+			case did.KeyAgreement:
+				doc.KeyAgreement = append(doc.KeyAgreement, *verification)
+			}
+		}
+		return nil
+	}
+	switch suffix[0] {
+	case '0':
+		err := addKey("#"+suffix[1:], suffix[1:], did.Authentication, did.AssertionMethod,
+			did.CapabilityDelegation, did.CapabilityInvocation)
+		if err != nil {
+			return nil, err
+		}
+	case '2':
+		purposes := map[byte]did.VerificationRelationship{
+			'A': did.AssertionMethod,
+			'V': did.Authentication,
+			'E': did.KeyAgreement,
+			'I': did.CapabilityInvocation,
+			'D': did.CapabilityDelegation,
+		}
+		count := 0
+		for _, element := range strings.Split(suffix[1:], ".") {
+			if element == "" {
+				continue
+			}
+			if element[0] == 'S' {
+				// Services do not bear on signature verification.
+				continue
+			}
+			relationship, okay := purposes[element[0]]
+			if !okay {
+				return nil, fmt.Errorf("unsupported did:peer purpose: %c", element[0])
+			}
+			count++
+			err := addKey(fmt.Sprintf("#key-%d", count), element[1:], relationship)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported did:peer numeric algorithm: %c", suffix[0])
+	}
+	return doc, nil
+}
+
+// verificationMethodFromKey uses the verification method types that the did-go documents
+// serialize faithfully, which are also those of did:key resolution.
+func verificationMethodFromKey(id string, controller string, keyType KeyType, pubBytes []byte) (*did.VerificationMethod, error) {
+	switch keyType {
+	case Ed25519:
+		return did.NewVerificationMethodFromBytesWithMultibase(id, "Ed25519VerificationKey2020", controller,
+			append(prefixBytes(keyType), pubBytes...), multibase.Base58BTC), nil
+	case Bls12381:
+		return did.NewVerificationMethodFromBytes(id, "Bls12381G2Key2020", controller, pubBytes), nil
+	case Bls12381G1:
+		return did.NewVerificationMethodFromBytes(id, "Bls12381G1Key2020", controller, pubBytes), nil
+	case Secp256k1:
+		return did.NewVerificationMethodFromBytes(id, "EcdsaSecp256k1VerificationKey2019", controller, pubBytes), nil
+	case P256:
+		j, err := publicJWK(keyType, pubBytes)
+		if err != nil {
+			return nil, err
+		}
+		jwkBytes, err := json.Marshal(j)
+		if err != nil {
+			return nil, err
+		}
+		var publicJWK jwk.JWK
+		err = json.Unmarshal(jwkBytes, &publicJWK)
+		if err != nil {
+			return nil, err
+		}
+		return did.NewVerificationMethodFromJWK(id, "JsonWebKey2020", controller, &publicJWK)
+	default:
+		return nil, fmt.Errorf("invalid key type: %v", keyType)
+	}
+}
@@ -0,0 +1,205 @@
+package key
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerify_DidWeb(t *testing.T) {
+	kEd, err := GenerateKey(Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kP256, err := GenerateKey(P256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicJWK, err := MarshalPublicJWK(Did(kP256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var didWeb string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/did.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{
+  "@context": ["https://www.w3.org/ns/did/v1"],
+  "id": %q,
+  "verificationMethod": [
+    {"id": "#ed", "type": "Multikey", "controller": %q, "publicKeyMultibase": %q},
+    {"id": "#p256", "type": "JsonWebKey2020", "controller": %q, "publicKeyJwk": %s}
+  ],
+  "assertionMethod": ["#ed", "#p256"]
+}`, didWeb, didWeb, strings.TrimPrefix(Did(kEd), "did:key:"), didWeb, publicJWK)
+	}))
+	defer server.Close()
+	host, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	didWeb = "did:web:" + url.QueryEscape(host.Host)
+	DefaultRegistry.Register("web", &WebResolver{Client: server.Client()})
+	defer DefaultRegistry.Register("web", NewWebResolver())
+
+	message := []byte("message")
+	sigEd, err := kEd.Sign(message, didWeb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigP256, err := kP256.Sign(message, didWeb+"#p256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(didWeb, sigEd, message, didWeb); err != nil {
+		t.Errorf("did:web assertion method failed verification: %v", err)
+	}
+	if err := Verify(didWeb+"#p256", sigP256, message, didWeb+"#p256"); err != nil {
+		t.Errorf("did:web key ID failed verification: %v", err)
+	}
+	if err := Verify(didWeb+"#ed", sigP256, message, didWeb+"#p256"); err == nil {
+		t.Error("signature verified under the wrong key ID")
+	}
+	if err := Verify(didWeb+"#missing", sigEd, message, didWeb); err == nil {
+		t.Error("signature verified under a missing key ID")
+	}
+	resolution, err := ResolveDid(didWeb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := json.Marshal(resolution); err != nil || resolution.DIDDocument.ID != didWeb {
+		t.Error("incorrect did:web resolution")
+	}
+}
+
+func TestVerify_DidPeer(t *testing.T) {
+	kAssert, err := GenerateKey(Secp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kAgree, err := GenerateKey(Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	multikey := func(k Key) string {
+		return strings.TrimPrefix(Did(k), "did:key:")
+	}
+	message := []byte("message")
+	for _, keyType := range []KeyType{Ed25519, Bls12381, Secp256k1, P256} {
+		k, err := GenerateKey(keyType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, didPeer := range []string{
+			"did:peer:0" + multikey(k),
+			"did:peer:2.E" + multikey(kAgree) + ".A" + multikey(k) + ".SeyJ0IjoiZG0iLCJzIjoiaHR0cHM6Ly9leGFtcGxlLmNvbSJ9",
+		} {
+			sig, err := k.Sign(message, didPeer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Verify(didPeer, sig, message, didPeer); err != nil {
+				t.Errorf("%v failed verification: %v", didPeer, err)
+			}
+			resolution, err := ResolveDid(didPeer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := resolution.JSONBytes(); err != nil {
+				t.Errorf("%v failed serialization: %v", didPeer, err)
+			}
+		}
+	}
+	didPeer := "did:peer:2.E" + multikey(kAgree) + ".A" + multikey(kAssert)
+	sig, err := kAgree.Sign(message, didPeer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(didPeer, sig, message, didPeer); err == nil {
+		t.Error("key agreement key accepted as assertion method")
+	}
+	if err := Verify(didPeer+"#key-1", sig, message, didPeer); err == nil {
+		t.Error("key agreement key accepted by its key ID")
+	}
+	sig, err = kAssert.Sign(message, didPeer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(didPeer+"#key-2", sig, message, didPeer); err != nil {
+		t.Errorf("key ID failed verification: %v", err)
+	}
+}
+
+func TestRegistry_CacheAndPin(t *testing.T) {
+	k, err := GenerateKey(Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var docBytes []byte
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(docBytes)
+	}))
+	defer server.Close()
+	host, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	didWeb := DidWeb(host.Host, "")
+	doc := NewDidDocument(didWeb, false)
+	if err := doc.AddSigningKey(Did(k)); err != nil {
+		t.Fatal(err)
+	}
+	docBytes, err = doc.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("message")
+	sig, err := k.Sign(message, didWeb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := NewRegistry()
+	registry.Register("web", &WebResolver{Client: server.Client()})
+	for range 3 {
+		if _, err := registry.VerificationKeys(didWeb); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("resolved DID document %v times instead of once", requests)
+	}
+
+	offline := NewRegistry()
+	offline.Disable("web")
+	if _, err := offline.Resolve(didWeb); err == nil {
+		t.Error("disabled did:web resolved")
+	}
+	docFile := filepath.Join(t.TempDir(), "did.json")
+	if err := os.WriteFile(docFile, docBytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := offline.PinFile(docFile); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := offline.VerificationKeys(didWeb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyPublic(keys[0].Type, keys[0].Public, sig, message, didWeb); err != nil {
+		t.Errorf("pinned DID document failed verification: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("pinned DID document was fetched")
+	}
+}
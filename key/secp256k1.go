@@ -0,0 +1,97 @@
+package key
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+type KeySecp256k1 struct {
+	Private *secp256k1.PrivateKey
+}
+
+func generateSecp256k1() (*KeySecp256k1, error) {
+	pri, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return makeSecp256k1(pri)
+}
+
+func makeSecp256k1(pri *secp256k1.PrivateKey) (*KeySecp256k1, error) {
+	return &KeySecp256k1{
+		Private: pri,
+	}, nil
+}
+
+func fromBytesSecp256k1(priBytes []byte) (*KeySecp256k1, error) {
+	if len(priBytes) != 32 {
+		return nil, fmt.Errorf("incorrect length of secp256k1 private key: %v", len(priBytes))
+	}
+	var scalar secp256k1.ModNScalar
+	overflow := scalar.SetByteSlice(priBytes)
+	if overflow || scalar.IsZero() {
+		return nil, fmt.Errorf("invalid secp256k1 private key")
+	}
+	return makeSecp256k1(secp256k1.NewPrivateKey(&scalar))
+}
+
+func (k *KeySecp256k1) keyType() KeyType {
+	return Secp256k1
+}
+
+func (k *KeySecp256k1) PrivateBytes() []byte {
+	return k.Private.Serialize()
+}
+
+func (k *KeySecp256k1) PublicBytes() []byte {
+	return k.Private.PubKey().SerializeCompressed()
+}
+
+func (k *KeySecp256k1) Sign(message []byte, context string) ([]byte, error) {
+	digest, err := digestEcdsa(message, context)
+	if err != nil {
+		return nil, err
+	}
+	return k.signDigest(digest)
+}
+
+// signDigest relies on the decred signer to produce a low S.
+func (k *KeySecp256k1) signDigest(digest []byte) ([]byte, error) {
+	sig := ecdsa.Sign(k.Private, digest)
+	r := sig.R()
+	s := sig.S()
+	rBytes := r.Bytes()
+	sBytes := s.Bytes()
+	return append(rBytes[:], sBytes[:]...), nil
+}
+
+func verifySecp256k1(pubBytes []byte, sig []byte, message []byte, context string) error {
+	digest, err := digestEcdsa(message, context)
+	if err != nil {
+		return err
+	}
+	return verifyDigestSecp256k1(pubBytes, sig, digest)
+}
+
+func verifyDigestSecp256k1(pubBytes []byte, sig []byte, digest []byte) error {
+	pub, err := secp256k1.ParsePubKey(pubBytes)
+	if err != nil {
+		return err
+	}
+	if len(sig) != 64 {
+		return fmt.Errorf("incorrect length of ECDSA signature: %v", len(sig))
+	}
+	var r, s secp256k1.ModNScalar
+	if r.SetByteSlice(sig[:32]) || s.SetByteSlice(sig[32:]) {
+		return fmt.Errorf("invalid ECDSA signature")
+	}
+	if s.IsOverHalfOrder() {
+		return fmt.Errorf("ECDSA signature has a high S")
+	}
+	if !ecdsa.NewSignature(&r, &s).Verify(digest, pub) {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package key
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestGenerateKey_Secp256k1(t *testing.T) {
+	testGenerateKey(Secp256k1, reflect.TypeOf(&KeySecp256k1{}), t)
+}
+
+func TestDid_Secp256k1(t *testing.T) {
+	testDid(Secp256k1, "did:key:zQ3s", t)
+}
+
+func TestPubFromDid_Secp256k1(t *testing.T) {
+	testPubFromDid(Secp256k1, t)
+}
+
+func TestDidResolution_Secp256k1(t *testing.T) {
+	testDidResolution(Secp256k1, "EcdsaSecp256k1VerificationKey2019", t)
+}
+
+func TestIO_Secp256k1(t *testing.T) {
+	testIO(
+		Secp256k1,
+		func(kx Key, ky Key) {
+			kx1, ok := kx.(*KeySecp256k1)
+			if !ok {
+				t.Errorf("incorrect key type generated: %T", kx1)
+			}
+			ky1, ok := ky.(*KeySecp256k1)
+			if !ok {
+				t.Errorf("incorrect key type read: %T", ky1)
+			}
+			if !bytes.Equal(kx1.PrivateBytes(), ky1.PrivateBytes()) {
+				t.Error("private key does not match")
+			}
+		},
+		t,
+	)
+}
+
+func TestVerify_Secp256k1(t *testing.T) {
+	testVerify(Secp256k1, t)
+}
+
+func TestContextSeparation_Secp256k1(t *testing.T) {
+	testContextSeparation(Secp256k1, t)
+}
+
+func TestLowS_Secp256k1(t *testing.T) {
+	testLowS(Secp256k1, secp256k1.S256().N, t)
+}
@@ -0,0 +1,187 @@
+package key
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// A KeyShareBls12381 is one of the Shamir shares of a BLS12-381 group key. Signing with
+// the share produces a partial signature, and a threshold of partial signatures for the
+// same message combine into a signature that verifies under the DID of the group.
+type KeyShareBls12381 struct {
+	KeyBls12381
+	Index uint32
+	Group bls12381.PointG2
+}
+
+type PartialSignature struct {
+	Group     string
+	Index     uint32
+	Signature []byte
+}
+
+const blockTypeShare = "BLS12-381 PRIVATE KEY SHARE"
+
+// DealBls12381 generates a BLS12-381 group key and splits it into shares, any threshold
+// of which can sign on behalf of the group. The group private key is not retained.
+func DealBls12381(threshold int, shares int) ([]*KeyShareBls12381, error) {
+	if threshold < 1 || threshold > shares {
+		return nil, fmt.Errorf("threshold must be between 1 and the number of shares")
+	}
+	order := bls12381.NewG1().Q()
+	coefficients := make([]*big.Int, threshold)
+	for i := range coefficients {
+		c, err := rand.Int(rand.Reader, order)
+		if err != nil {
+			return nil, err
+		}
+		coefficients[i] = c
+	}
+	group, err := makeBls12381(frFromBig(coefficients[0]))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*KeyShareBls12381, shares)
+	for i := range result {
+		index := uint32(i + 1)
+		// Evaluate the polynomial at the index using Horner's method.
+		y := new(big.Int)
+		x := new(big.Int).SetUint64(uint64(index))
+		for j := threshold - 1; j >= 0; j-- {
+			y.Mul(y, x).Add(y, coefficients[j]).Mod(y, order)
+		}
+		result[i], err = makeKeyShare(frFromBig(y), index, &group.Public)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func makeKeyShare(pri *bls12381.Fr, index uint32, group *bls12381.PointG2) (*KeyShareBls12381, error) {
+	if index == 0 {
+		return nil, fmt.Errorf("share index must be positive")
+	}
+	k, err := makeBls12381(pri)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyShareBls12381{
+		KeyBls12381: *k,
+		Index:       index,
+		Group:       *group,
+	}, nil
+}
+
+func frFromBig(x *big.Int) *bls12381.Fr {
+	return bls12381.NewFr().FromBytes(x.FillBytes(make([]byte, 32)))
+}
+
+// GroupDid is the DID under which combined signatures verify.
+func (share *KeyShareBls12381) GroupDid() string {
+	return didFromPublic(Bls12381, bls12381.NewG2().ToCompressed(&share.Group))
+}
+
+func (share *KeyShareBls12381) SignPartial(message []byte, context string) (*PartialSignature, error) {
+	sig, err := share.Sign(message, context)
+	if err != nil {
+		return nil, err
+	}
+	return &PartialSignature{
+		Group:     share.GroupDid(),
+		Index:     share.Index,
+		Signature: sig,
+	}, nil
+}
+
+// CombineBls12381 interpolates partial signatures from distinct shares of the same group
+// into a group signature. The result only verifies if at least the threshold of shares signed.
+func CombineBls12381(partials []PartialSignature) ([]byte, error) {
+	if len(partials) == 0 {
+		return nil, fmt.Errorf("no partial signatures")
+	}
+	order := bls12381.NewG1().Q()
+	indices := make(map[uint32]bool)
+	for _, partial := range partials {
+		if partial.Group != partials[0].Group {
+			return nil, fmt.Errorf("partial signatures are for different groups")
+		}
+		if partial.Index == 0 || indices[partial.Index] {
+			return nil, fmt.Errorf("invalid or duplicate share index: %v", partial.Index)
+		}
+		indices[partial.Index] = true
+	}
+	g1 := bls12381.NewG1()
+	combined := g1.Zero()
+	for _, partial := range partials {
+		sig, err := pointG1FromBytesBls12381(partial.Signature)
+		if err != nil {
+			return nil, err
+		}
+		// The Lagrange coefficient for interpolating the polynomial at zero.
+		numerator := big.NewInt(1)
+		denominator := big.NewInt(1)
+		xi := new(big.Int).SetUint64(uint64(partial.Index))
+		for _, other := range partials {
+			if other.Index == partial.Index {
+				continue
+			}
+			xj := new(big.Int).SetUint64(uint64(other.Index))
+			numerator.Mul(numerator, xj).Mod(numerator, order)
+			denominator.Mul(denominator, new(big.Int).Sub(xj, xi)).Mod(denominator, order)
+		}
+		lambda := numerator.Mul(numerator, denominator.ModInverse(denominator, order)).Mod(numerator, order)
+		g1.Add(combined, combined, g1.MulScalarBig(g1.New(), sig, lambda))
+	}
+	return g1.ToCompressed(combined), nil
+}
+
+func encodeKeyShare(share *KeyShareBls12381) *pem.Block {
+	bytes := binary.BigEndian.AppendUint32(nil, share.Index)
+	bytes = append(bytes, share.PrivateBytes()...)
+	bytes = append(bytes, bls12381.NewG2().ToCompressed(&share.Group)...)
+	return &pem.Block{
+		Type:  blockTypeShare,
+		Bytes: bytes,
+	}
+}
+
+func decodeKeyShare(block *pem.Block) (*KeyShareBls12381, error) {
+	if block.Type != blockTypeShare {
+		return nil, fmt.Errorf("wrong PEM block type")
+	}
+	if len(block.Bytes) != 4+32+96 {
+		return nil, fmt.Errorf("incorrect length of BLS12-381 key share: %v", len(block.Bytes))
+	}
+	group, err := pointG2FromBytesBls12381(block.Bytes[36:])
+	if err != nil {
+		return nil, err
+	}
+	pri := bls12381.NewFr().FromBytes(block.Bytes[4:36])
+	return makeKeyShare(pri, binary.BigEndian.Uint32(block.Bytes[:4]), group)
+}
+
+func WriteKeyShare(share *KeyShareBls12381, filename string) error {
+	return writePemFile(encodeKeyShare(share), filename)
+}
+
+func WriteEncryptedKeyShare(share *KeyShareBls12381, filename string, passphrase []byte) error {
+	block, err := encryptBlock(encodeKeyShare(share), passphrase)
+	if err != nil {
+		return err
+	}
+	return writePemFile(block, filename)
+}
+
+func ReadKeyShareWithPassphrase(filename string, passphrase func() ([]byte, error)) (*KeyShareBls12381, error) {
+	block, err := readPemFile(filename, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return decodeKeyShare(block)
+}
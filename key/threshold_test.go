@@ -0,0 +1,58 @@
+package key
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestThresholdBls12381(t *testing.T) {
+	shares, err := DealBls12381(3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	group := shares[0].GroupDid()
+	message := []byte("threshold message")
+	var partials []PartialSignature
+	for _, i := range []int{4, 0, 2} {
+		partial, err := shares[i].SignPartial(message, group)
+		if err != nil {
+			t.Fatal(err)
+		}
+		partials = append(partials, *partial)
+	}
+	signature, err := CombineBls12381(partials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(group, signature, message, group); err != nil {
+		t.Fatalf("combined signature failed verification: %v", err)
+	}
+	signature, err = CombineBls12381(partials[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(group, signature, message, group); err == nil {
+		t.Fatal("signature combined from too few shares verified")
+	}
+	if _, err := CombineBls12381(append(partials, partials[0])); err == nil {
+		t.Fatal("duplicate share accepted")
+	}
+}
+
+func TestKeyShareRoundTrip(t *testing.T) {
+	shares, err := DealBls12381(2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "share.pem")
+	if err := WriteEncryptedKeyShare(shares[1], filename, []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	share, err := ReadKeyShareWithPassphrase(filename, func() ([]byte, error) { return []byte("secret"), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if share.Index != 2 || share.GroupDid() != shares[1].GroupDid() || Did(share) != Did(shares[1]) {
+		t.Fatal("key share did not round trip")
+	}
+}
@@ -0,0 +1,70 @@
+package ledger
+
+import (
+	"sort"
+
+	"github.com/ipfs/go-cid"
+)
+
+// Removal records a block that was visible from one tip but not the other, along
+// with the visible blocks whose rejections hide it. A removal without rejectors
+// is simply not reachable from the new tip.
+type Removal struct {
+	Block      cid.Cid
+	RejectedBy []cid.Cid
+}
+
+// Diff summarises how the visible blocks change when moving from one tip to another.
+type Diff struct {
+	FromTip    cid.Cid
+	ToTip      cid.Cid
+	Visible    []cid.Cid
+	Rejected   []Removal
+	Reaccepted []cid.Cid
+}
+
+// DiffLedgers compares the visible blocks of two ledgers. Blocks that are visible
+// only in the second ledger are newly visible, unless the first ledger already
+// contained them as rejected blocks, in which case they are re-accepted.
+func DiffLedgers(from *Ledger, to *Ledger) *Diff {
+	fromVisible := from.Reachable()
+	toVisible := to.Reachable()
+	toRejectors := to.Rejectors()
+	diff := Diff{
+		FromTip:    from.Tip,
+		ToTip:      to.Tip,
+		Visible:    []cid.Cid{},
+		Rejected:   []Removal{},
+		Reaccepted: []cid.Cid{},
+	}
+	for c := range toVisible {
+		if fromVisible[c] {
+			continue
+		}
+		if _, present := from.Headers[c]; present {
+			diff.Reaccepted = append(diff.Reaccepted, c)
+		} else {
+			diff.Visible = append(diff.Visible, c)
+		}
+	}
+	for c := range fromVisible {
+		if !toVisible[c] {
+			diff.Rejected = append(diff.Rejected, Removal{
+				Block:      c,
+				RejectedBy: toRejectors[c],
+			})
+		}
+	}
+	sortCids(diff.Visible)
+	sortCids(diff.Reaccepted)
+	sort.Slice(diff.Rejected, func(i, j int) bool {
+		return diff.Rejected[i].Block.String() < diff.Rejected[j].Block.String()
+	})
+	return &diff
+}
+
+func sortCids(cids []cid.Cid) {
+	sort.Slice(cids, func(i, j int) bool {
+		return cids[i].String() < cids[j].String()
+	})
+}
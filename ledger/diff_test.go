@@ -0,0 +1,105 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+)
+
+func TestDiff(t *testing.T) {
+
+	t.Run("Rejection by new tip", func(t *testing.T) {
+		hs := empty()
+		hs[c0] = *h0
+		hs[c1] = *h1
+		hs[c2] = *h2
+		from := Ledger{
+			Tip:     c2,
+			Headers: hs,
+		}
+		ht := empty()
+		ht[c0] = *h0
+		ht[c1] = *h1
+		ht[c2] = *h2
+		ht[c4] = *h4
+		to := Ledger{
+			Tip:     c4,
+			Headers: ht,
+		}
+		diff := DiffLedgers(&from, &to)
+		if !assertEqual(diff.Visible, []cid.Cid{c4}) {
+			t.Error("Incorrect newly visible blocks")
+		}
+		if len(diff.Reaccepted) != 0 {
+			t.Error("Incorrect re-accepted blocks")
+		}
+		if len(diff.Rejected) != 2 {
+			t.Fatal("Incorrect rejected blocks")
+		}
+		for _, removal := range diff.Rejected {
+			if removal.Block != c0 && removal.Block != c1 {
+				t.Errorf("Unexpected rejected block %v", removal.Block)
+			}
+			if !assertEqual(removal.RejectedBy, []cid.Cid{c4}) {
+				t.Errorf("Incorrect rejector for %v", removal.Block)
+			}
+		}
+	})
+
+	t.Run("Re-acceptance by new tip", func(t *testing.T) {
+		hs := empty()
+		hs[c0] = *h0
+		hs[c1] = *h1
+		hs[c2] = *h2
+		hs[c6] = *h6
+		hs[c7] = *h7
+		hs[c8] = *h8
+		hs[c9] = *h9
+		hs[c10] = *h10
+		hs[c11] = *h11
+		hs[c12] = *h12
+		from := Ledger{
+			Tip:     c12,
+			Headers: hs,
+		}
+		ht := empty()
+		for c, h := range hs {
+			ht[c] = h
+		}
+		ht[c13] = *h13
+		to := Ledger{
+			Tip:     c13,
+			Headers: ht,
+		}
+		diff := DiffLedgers(&from, &to)
+		if !assertEqual(diff.Visible, []cid.Cid{c13}) {
+			t.Error("Incorrect newly visible blocks")
+		}
+		if !assertEqual(diff.Reaccepted, []cid.Cid{c2, c6}) {
+			t.Error("Incorrect re-accepted blocks")
+		}
+		if len(diff.Rejected) != 0 {
+			t.Error("Incorrect rejected blocks")
+		}
+		reverse := DiffLedgers(&to, &from)
+		if len(reverse.Rejected) != 3 {
+			t.Fatal("Incorrect rejected blocks in reverse")
+		}
+		for _, removal := range reverse.Rejected {
+			var expected []cid.Cid
+			switch removal.Block {
+			case c2:
+				expected = []cid.Cid{c12}
+			case c6:
+				expected = []cid.Cid{c8}
+			case c13:
+				expected = []cid.Cid{}
+			default:
+				t.Errorf("Unexpected rejected block %v", removal.Block)
+			}
+			if !assertEqual(removal.RejectedBy, expected) {
+				t.Errorf("Incorrect rejector for %v", removal.Block)
+			}
+		}
+	})
+}
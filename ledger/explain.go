@@ -0,0 +1,148 @@
+package ledger
+
+import (
+	"maps"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/functionally/nacatgunma/header"
+)
+
+// Rejection explains why a block is invisible: following the path of accept edges
+// from the tip, the rejector rejects a block further along the path, and the invisible
+// block is the end of the path, at or beyond the rejected block.
+type Rejection struct {
+	Rejector cid.Cid
+	Rejected cid.Cid
+	Path     []cid.Cid
+}
+
+// Explain maps each invisible block to the rejections that hide it. As in Reachable, a
+// rejection applies only to the blocks reached through the rejector along accept
+// edges, so each path runs from the tip through the rejector to the rejected block and
+// then on to the invisible block.
+func (ledger *Ledger) Explain() map[cid.Cid][]Rejection {
+	visible := ledger.Reachable()
+	explanations := make(map[cid.Cid][]Rejection)
+	if _, present := ledger.Headers[ledger.Tip]; !present {
+		return explanations
+	}
+
+	// Track the blocks along the path and, for each block rejected so far, its rejector.
+	type path struct {
+		Blocks    []cid.Cid
+		Rejectors map[cid.Cid]cid.Cid
+	}
+	type explained struct {
+		Hidden   cid.Cid
+		Rejector cid.Cid
+		Rejected cid.Cid
+	}
+	seen := make(map[explained]bool)
+
+	// Breadth-first enumeration of paths, so each rejection is explained by a shortest path.
+	queue := []path{{
+		Blocks:    []cid.Cid{ledger.Tip},
+		Rejectors: make(map[cid.Cid]cid.Cid),
+	}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		block := current.Blocks[len(current.Blocks)-1]
+
+		// A rejected block ends the path, hiding the blocks only reachable through it.
+		if rejector, rejected := current.Rejectors[block]; rejected {
+			for hidden, chain := range ledger.acceptPaths(block) {
+				key := explained{Hidden: hidden, Rejector: rejector, Rejected: block}
+				if visible[hidden] || seen[key] {
+					continue
+				}
+				seen[key] = true
+				explanations[hidden] = append(explanations[hidden], Rejection{
+					Rejector: rejector,
+					Rejected: block,
+					Path:     append(append([]cid.Cid{}, current.Blocks...), chain[1:]...),
+				})
+			}
+			continue
+		}
+
+		hdr, present := ledger.Headers[block]
+		if !present {
+			continue
+		}
+		rejectors := current.Rejectors
+		if len(hdr.Payload.Reject) > 0 {
+			rejectors = maps.Clone(rejectors)
+			for _, reject := range hdr.Payload.Reject {
+				if _, already := rejectors[reject]; !already {
+					rejectors[reject] = block
+				}
+			}
+		}
+		for _, accept := range hdr.Payload.Accept {
+			queue = append(queue, path{
+				Blocks:    append(append([]cid.Cid{}, current.Blocks...), accept),
+				Rejectors: rejectors,
+			})
+		}
+	}
+	return explanations
+}
+
+// Export is a ledger together with the explanation of each invisible header, for serialization.
+type Export struct {
+	Tip        cid.Cid
+	Headers    map[cid.Cid]header.Header
+	Rejections map[cid.Cid][]Rejection
+}
+
+func (ledger *Ledger) Export() *Export {
+	return &Export{
+		Tip:        ledger.Tip,
+		Headers:    ledger.Headers,
+		Rejections: ledger.Explain(),
+	}
+}
+
+// Rejectors maps each invisible block to the visible blocks that reject it, or one of
+// its descendants along accept edges, on a path from the tip.
+func (ledger *Ledger) Rejectors() map[cid.Cid][]cid.Cid {
+	rejectors := make(map[cid.Cid][]cid.Cid)
+	for hidden, rejections := range ledger.Explain() {
+		seen := make(map[cid.Cid]bool)
+		for _, rejection := range rejections {
+			if !seen[rejection.Rejector] {
+				seen[rejection.Rejector] = true
+				rejectors[hidden] = append(rejectors[hidden], rejection.Rejector)
+			}
+		}
+	}
+	return rejectors
+}
+
+// acceptPaths finds a shortest accept-path from a block to each of its ancestors in the ledger.
+func (ledger *Ledger) acceptPaths(start cid.Cid) map[cid.Cid][]cid.Cid {
+	paths := make(map[cid.Cid][]cid.Cid)
+	if _, present := ledger.Headers[start]; !present {
+		return paths
+	}
+	paths[start] = []cid.Cid{start}
+	queue := []cid.Cid{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, accept := range ledger.Headers[current].Payload.Accept {
+			if _, present := ledger.Headers[accept]; !present {
+				continue
+			}
+			if _, seen := paths[accept]; seen {
+				continue
+			}
+			path := append(append([]cid.Cid{}, paths[current]...), accept)
+			paths[accept] = path
+			queue = append(queue, accept)
+		}
+	}
+	return paths
+}
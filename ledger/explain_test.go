@@ -0,0 +1,91 @@
+package ledger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/functionally/nacatgunma/header"
+)
+
+func TestExplain(t *testing.T) {
+
+	t.Run("Deeper rejector", func(t *testing.T) {
+		hs := empty()
+		hs[c0] = *h0
+		hs[c1] = *h1
+		hs[c2] = *h2
+		hs[c6] = *h6
+		hs[c7] = *h7
+		hs[c8] = *h8
+		hs[c9] = *h9
+		hs[c10] = *h10
+		hs[c11] = *h11
+		hs[c12] = *h12
+		le := Ledger{
+			Tip:     c12,
+			Headers: hs,
+		}
+		explanations := le.Explain()
+		if len(explanations) != 2 {
+			t.Fatalf("Incorrect number of explanations: %v", len(explanations))
+		}
+		c6s := explanations[c6]
+		if len(c6s) != 1 || c6s[0].Rejector != c8 || c6s[0].Rejected != c6 || !reflect.DeepEqual(c6s[0].Path, []cid.Cid{c12, c11, c8, c7, c6}) {
+			t.Error("Incorrect explanation of rejection by a deeper block")
+		}
+		c2s := explanations[c2]
+		if len(c2s) != 1 || c2s[0].Rejector != c12 || !reflect.DeepEqual(c2s[0].Path, []cid.Cid{c12, c11, c8, c7, c2}) {
+			t.Error("Incorrect explanation of rejection by the tip")
+		}
+	})
+
+	t.Run("Rejected ancestors", func(t *testing.T) {
+		hs := empty()
+		hs[c0] = *h0
+		hs[c1] = *h1
+		hs[c2] = *h2
+		hs[c5] = *h5
+		le := Ledger{
+			Tip:     c5,
+			Headers: hs,
+		}
+		explanations := le.Explain()
+		c0s := explanations[c0]
+		if len(c0s) != 1 || c0s[0].Rejector != c5 || c0s[0].Rejected != c2 || !reflect.DeepEqual(c0s[0].Path, []cid.Cid{c5, c2, c1, c0}) {
+			t.Error("Incorrect explanation of a rejected ancestor")
+		}
+		rejectors := le.Rejectors()
+		if !assertEqual(rejectors[c1], []cid.Cid{c5}) {
+			t.Error("Incorrect rejectors")
+		}
+	})
+	t.Run("Rejection off the path", func(t *testing.T) {
+		cG, hG := makeHeader([]cid.Cid{}, []cid.Cid{})
+		cX, hX := makeHeader([]cid.Cid{cG}, []cid.Cid{})
+		cY, hY := makeHeader([]cid.Cid{cX}, []cid.Cid{})
+		cQ, hQ := makeHeader([]cid.Cid{cY}, []cid.Cid{cX})
+		cR, hR := makeHeader([]cid.Cid{cG}, []cid.Cid{cX})
+		cT, hT := makeHeader([]cid.Cid{cQ, cR}, []cid.Cid{})
+		le := Ledger{
+			Tip: cT,
+			Headers: map[cid.Cid]header.Header{
+				cG: *hG,
+				cX: *hX,
+				cY: *hY,
+				cQ: *hQ,
+				cR: *hR,
+				cT: *hT,
+			},
+		}
+		explanations := le.Explain()
+		if len(explanations) != 1 {
+			t.Fatalf("Incorrect number of explanations: %v", len(explanations))
+		}
+		cXs := explanations[cX]
+		if len(cXs) != 1 || cXs[0].Rejector != cQ || !reflect.DeepEqual(cXs[0].Path, []cid.Cid{cT, cQ, cY, cX}) {
+			t.Errorf("Rejection by a block that does not accept the rejected block reported: %v", cXs)
+		}
+	})
+}
@@ -1,9 +1,16 @@
 package ledger
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/functionally/nacatgunma/header"
 	"github.com/ipfs/go-cid"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/path"
 	"gonum.org/v1/gonum/graph/simple"
+	"gonum.org/v1/gonum/graph/topo"
+	"gonum.org/v1/gonum/graph/traverse"
 )
 
 type HeaderNode struct {
@@ -19,12 +26,17 @@ func (node HeaderNode) ID() int64 {
 type HeaderTable struct {
 	FromIndex map[int64]*HeaderNode
 	FromCid   map[cid.Cid]*HeaderNode
+	accepts   *simple.DirectedGraph
 }
 
 func (ledger *Ledger) MakeHeaderTable() *HeaderTable {
-	var table HeaderTable
+	table := HeaderTable{
+		FromIndex: make(map[int64]*HeaderNode),
+		FromCid:   make(map[cid.Cid]*HeaderNode),
+	}
 	var i int64 = 0
-	for hdrCid, hdr := range ledger.Headers {
+	for _, hdrCid := range sortedCids(ledger.Headers) {
+		hdr := ledger.Headers[hdrCid]
 		node := HeaderNode{
 			Index:     i,
 			HeaderCid: hdrCid,
@@ -34,9 +46,12 @@ func (ledger *Ledger) MakeHeaderTable() *HeaderTable {
 		table.FromCid[hdrCid] = &node
 		i++
 	}
+	table.accepts = table.MakeDirectedGraph(false)
 	return &table
 }
 
+// MakeDirectedGraph builds the graph of accept edges, pointing from each block to
+// its parents unless reversed. Parents missing from the table are omitted.
 func (table *HeaderTable) MakeDirectedGraph(reverse bool) *simple.DirectedGraph {
 	graph := simple.NewDirectedGraph()
 	for _, node := range table.FromIndex {
@@ -44,12 +59,150 @@ func (table *HeaderTable) MakeDirectedGraph(reverse bool) *simple.DirectedGraph
 	}
 	for _, node := range table.FromCid {
 		for _, acceptCid := range node.Header.Payload.Accept {
+			parent, present := table.FromCid[acceptCid]
+			if !present {
+				continue
+			}
 			if reverse {
-				graph.SetEdge(graph.NewEdge(table.FromCid[acceptCid], node))
+				graph.SetEdge(graph.NewEdge(parent, node))
 			} else {
-				graph.SetEdge(graph.NewEdge(node, table.FromCid[acceptCid]))
+				graph.SetEdge(graph.NewEdge(node, parent))
 			}
 		}
 	}
 	return graph
 }
+
+func (table *HeaderTable) node(c cid.Cid) (*HeaderNode, error) {
+	node, present := table.FromCid[c]
+	if !present {
+		return nil, fmt.Errorf("block %v is not in the ledger", c)
+	}
+	return node, nil
+}
+
+// Ancestors returns the blocks reachable from a block along accept edges, including the block itself.
+func (table *HeaderTable) Ancestors(c cid.Cid) (map[cid.Cid]bool, error) {
+	node, err := table.node(c)
+	if err != nil {
+		return nil, err
+	}
+	ancestors := make(map[cid.Cid]bool)
+	var walk traverse.BreadthFirst
+	walk.Walk(table.accepts, node, func(n graph.Node, _ int) bool {
+		ancestors[n.(*HeaderNode).HeaderCid] = true
+		return false
+	})
+	return ancestors, nil
+}
+
+// IsAncestor reports whether one block is reachable from another along accept edges.
+// Every block is its own ancestor.
+func (table *HeaderTable) IsAncestor(ancestor cid.Cid, descendant cid.Cid) (bool, error) {
+	from, err := table.node(descendant)
+	if err != nil {
+		return false, err
+	}
+	to, err := table.node(ancestor)
+	if err != nil {
+		return false, err
+	}
+	return topo.PathExistsIn(table.accepts, from, to), nil
+}
+
+// CommonAncestors returns the lowest common ancestors of the blocks: the common
+// ancestors that are not themselves ancestors of another common ancestor.
+func (table *HeaderTable) CommonAncestors(cids []cid.Cid) ([]cid.Cid, error) {
+	if len(cids) == 0 {
+		return nil, fmt.Errorf("no blocks for common ancestry")
+	}
+	common, err := table.Ancestors(cids[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cids[1:] {
+		ancestors, err := table.Ancestors(c)
+		if err != nil {
+			return nil, err
+		}
+		for candidate := range common {
+			if !ancestors[candidate] {
+				delete(common, candidate)
+			}
+		}
+	}
+	lowest := []cid.Cid{}
+	for candidate := range common {
+		dominated := false
+		for other := range common {
+			if other == candidate {
+				continue
+			}
+			isAncestor, err := table.IsAncestor(candidate, other)
+			if err != nil {
+				return nil, err
+			}
+			if isAncestor {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			lowest = append(lowest, candidate)
+		}
+	}
+	sortCids(lowest)
+	return lowest, nil
+}
+
+// ShortestPath returns a shortest accept-path between two blocks, running from
+// the descendant to the ancestor whichever order the blocks are given in.
+func (table *HeaderTable) ShortestPath(from cid.Cid, to cid.Cid) ([]cid.Cid, error) {
+	fromNode, err := table.node(from)
+	if err != nil {
+		return nil, err
+	}
+	toNode, err := table.node(to)
+	if err != nil {
+		return nil, err
+	}
+	nodes, _ := path.DijkstraFrom(fromNode, table.accepts).To(toNode.ID())
+	if len(nodes) == 0 {
+		nodes, _ = path.DijkstraFrom(toNode, table.accepts).To(fromNode.ID())
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no accept-path between %v and %v", from, to)
+	}
+	cids := make([]cid.Cid, len(nodes))
+	for i, n := range nodes {
+		cids[i] = n.(*HeaderNode).HeaderCid
+	}
+	return cids, nil
+}
+
+// TopologicalOrder lists the blocks with every block after the blocks it accepts,
+// breaking ties by CID.
+func (table *HeaderTable) TopologicalOrder() ([]cid.Cid, error) {
+	nodes, err := topo.SortStabilized(table.MakeDirectedGraph(true), func(nodes []graph.Node) {
+		sort.Slice(nodes, func(i, j int) bool {
+			return nodes[i].ID() < nodes[j].ID()
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ledger is not acyclic: %w", err)
+	}
+	cids := make([]cid.Cid, len(nodes))
+	for i, n := range nodes {
+		cids[i] = n.(*HeaderNode).HeaderCid
+	}
+	return cids, nil
+}
+
+func sortedCids(headers map[cid.Cid]header.Header) []cid.Cid {
+	var cids []cid.Cid
+	for c := range headers {
+		cids = append(cids, c)
+	}
+	sortCids(cids)
+	return cids
+}
@@ -0,0 +1,96 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+)
+
+func TestAncestry(t *testing.T) {
+	hs := empty()
+	hs[c0] = *h0
+	hs[c1] = *h1
+	hs[c2] = *h2
+	hs[c6] = *h6
+	hs[c7] = *h7
+	hs[c8] = *h8
+	hs[c9] = *h9
+	hs[c10] = *h10
+	hs[c11] = *h11
+	le := Ledger{
+		Tip:     c11,
+		Headers: hs,
+	}
+	table := le.MakeHeaderTable()
+
+	t.Run("Ancestors", func(t *testing.T) {
+		ancestors, err := table.Ancestors(c7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cids []cid.Cid
+		for c := range ancestors {
+			cids = append(cids, c)
+		}
+		if !assertEqual(cids, []cid.Cid{c0, c1, c2, c6, c7}) {
+			t.Error("Incorrect ancestors")
+		}
+	})
+
+	t.Run("Is ancestor", func(t *testing.T) {
+		yes, err := table.IsAncestor(c6, c11)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !yes {
+			t.Error("Missing ancestor")
+		}
+		no, err := table.IsAncestor(c9, c8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if no {
+			t.Error("Spurious ancestor")
+		}
+		if _, err := table.IsAncestor(c12, c11); err == nil {
+			t.Error("Unknown block accepted")
+		}
+	})
+
+	t.Run("Common ancestors", func(t *testing.T) {
+		lowest, err := table.CommonAncestors([]cid.Cid{c8, c10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !assertEqual(lowest, []cid.Cid{c1}) {
+			t.Error("Incorrect lowest common ancestor")
+		}
+		lowest, err = table.CommonAncestors([]cid.Cid{c2, c6})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !assertEqual(lowest, []cid.Cid{c1}) {
+			t.Error("Incorrect lowest common ancestor")
+		}
+		lowest, err = table.CommonAncestors([]cid.Cid{c11, c7})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !assertEqual(lowest, []cid.Cid{c7}) {
+			t.Error("Incorrect lowest common ancestor")
+		}
+	})
+
+	t.Run("Shortest path", func(t *testing.T) {
+		path, err := table.ShortestPath(c0, c11)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(path) != 5 || path[0] != c11 || path[4] != c0 {
+			t.Errorf("Incorrect shortest path of length %v", len(path))
+		}
+		if _, err := table.ShortestPath(c8, c10); err == nil {
+			t.Error("Spurious path")
+		}
+	})
+}
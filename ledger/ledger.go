@@ -34,6 +34,28 @@ func ReadLedger(tip string, headerDir string) (*Ledger, error) {
 	return &ledger, nil
 }
 
+// ReadLedgers reads the headers reachable from several tips into one ledger whose tip is the first of them.
+func ReadLedgers(tips []string, headerDir string) (*Ledger, error) {
+	if len(tips) == 0 {
+		return nil, fmt.Errorf("no tips for ledger")
+	}
+	ledger, err := ReadLedger(tips[0], headerDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, tip := range tips[1:] {
+		tipCid, err := cid.Parse(tip)
+		if err != nil {
+			return nil, err
+		}
+		err = ledger.fillLedger(tipCid, headerDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ledger, nil
+}
+
 func (ledger *Ledger) fillLedger(headerCid cid.Cid, headerDir string) error {
 	headerFile := filepath.Join(headerDir, headerCid.String())
 	headerBytes, err := os.ReadFile(headerFile)
@@ -60,6 +82,38 @@ func (ledger *Ledger) fillLedger(headerCid cid.Cid, headerDir string) error {
 	return nil
 }
 
+// Validate verifies the signature and any delegation of every header in the ledger, as
+// of now, and checks that no header is timestamped before a header that it accepts,
+// directly or through headers without timestamps.
+func (ledger *Ledger) Validate() error {
+	for hdrCid, hdr := range ledger.Headers {
+		okay, err := hdr.Verify()
+		if err != nil {
+			return fmt.Errorf("invalid header %v: %w", hdrCid, err)
+		} else if !okay {
+			return fmt.Errorf("invalid header %v", hdrCid)
+		}
+	}
+	order, err := ledger.MakeHeaderTable().TopologicalOrder()
+	if err != nil {
+		return err
+	}
+	// The latest timestamp among each header and the headers it accepts.
+	latest := make(map[cid.Cid]int64)
+	for _, hdrCid := range order {
+		hdr := ledger.Headers[hdrCid]
+		var earliest int64
+		for _, acceptCid := range hdr.Payload.Accept {
+			earliest = max(earliest, latest[acceptCid])
+		}
+		if hdr.Payload.Timestamp != 0 && hdr.Payload.Timestamp < earliest {
+			return fmt.Errorf("invalid header %v: timestamped before an accepted header", hdrCid)
+		}
+		latest[hdrCid] = max(earliest, hdr.Payload.Timestamp)
+	}
+	return nil
+}
+
 func (ledger *Ledger) Bodies() []cid.Cid {
 	var bodies []cid.Cid
 	for _, hdr := range ledger.Headers {
@@ -159,7 +213,8 @@ func (ledger *Ledger) Prunable() map[cid.Cid]bool {
 	return rejected
 }
 
-func (ledger *Ledger) WriteLedgerTurtle(outputFile string, prunable map[cid.Cid]bool) error {
+// WriteLedgerTurtle writes the headers as Turtle, recording the blocks that reject each invisible header.
+func (ledger *Ledger) WriteLedgerTurtle(outputFile string, rejectors map[cid.Cid][]cid.Cid) error {
 	f, err := os.OpenFile(outputFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
 	if err != nil {
 		return nil
@@ -170,8 +225,7 @@ func (ledger *Ledger) WriteLedgerTurtle(outputFile string, prunable map[cid.Cid]
 		return nil
 	}
 	for headerCid, hdr := range ledger.Headers {
-		_, prune := prunable[headerCid]
-		err = writeHeaderTurtle(f, headerCid, &hdr, prune, ledger.Tip)
+		err = writeHeaderTurtle(f, headerCid, &hdr, rejectors[headerCid])
 		if err != nil {
 			return nil
 		}
@@ -192,7 +246,7 @@ func writePrefixTurtle(f *os.File) error {
 	return nil
 }
 
-func writeHeaderTurtle(f *os.File, hdrCid cid.Cid, hdr *header.Header, prune bool, tipCid cid.Cid) error {
+func writeHeaderTurtle(f *os.File, hdrCid cid.Cid, hdr *header.Header, rejectors []cid.Cid) error {
 	_, err := f.WriteString(fmt.Sprintf(
 		`
 cid:%v a :Header
@@ -229,11 +283,17 @@ cid:%v a :Header
 			return err
 		}
 	}
-	if prune {
-		_, err = f.WriteString(fmt.Sprintf("  ]\n; :rejectedBy cid:%v\n.", tipCid))
-	} else {
-		_, err = f.WriteString("  ]\n.")
+	_, err = f.WriteString("  ]\n")
+	if err != nil {
+		return err
+	}
+	for _, rejectorCid := range rejectors {
+		_, err = f.WriteString(fmt.Sprintf("; :rejectedBy cid:%v\n", rejectorCid))
+		if err != nil {
+			return err
+		}
 	}
+	_, err = f.WriteString(".")
 	if err != nil {
 		return err
 	}
@@ -387,3 +387,74 @@ func TestPrune(t *testing.T) {
 		}
 	})
 }
+
+func TestValidate(t *testing.T) {
+	hs := empty()
+	hs[c0] = *h0
+	hs[c1] = *h1
+	hs[c2] = *h2
+	le := Ledger{
+		Tip:     c2,
+		Headers: hs,
+	}
+	if err := le.Validate(); err != nil {
+		t.Error(err)
+	}
+	forged := *h2
+	forged.Signature = h1.Signature
+	hs[c2] = forged
+	if le.Validate() == nil {
+		t.Error("Forged header validated")
+	}
+}
+
+func TestValidateTimestamps(t *testing.T) {
+	body, _ := cid.Parse("bafyreih3lbpdqibixvdr3twiqwqrx3tgxbcwuooaq6ieyxzjzkw5zoxb3m")
+	ky, _ := key.GenerateKey(key.Ed25519)
+	sign := func(timestamp int64, accept []cid.Cid) (cid.Cid, *header.Header) {
+		payload := header.Payload{
+			Version:   1,
+			Body:      body,
+			Accept:    accept,
+			Timestamp: timestamp,
+		}
+		hdr, _ := payload.Sign(ky)
+		hdrBytes, _ := hdr.Marshal()
+		hdrCid, _ := ipfs.CidV1(hdrBytes)
+		return *hdrCid, hdr
+	}
+	parentCid, parent := sign(2000, nil)
+	for _, test := range []struct {
+		timestamp int64
+		valid     bool
+	}{
+		{2000, true},
+		{3000, true},
+		{1000, false},
+	} {
+		childCid, child := sign(test.timestamp, []cid.Cid{parentCid})
+		le := Ledger{
+			Tip: childCid,
+			Headers: map[cid.Cid]header.Header{
+				parentCid: *parent,
+				childCid:  *child,
+			},
+		}
+		if err := le.Validate(); (err == nil) != test.valid {
+			t.Errorf("Timestamp %v validated as %v: %v", test.timestamp, err == nil, err)
+		}
+	}
+	middleCid, middle := sign(0, []cid.Cid{parentCid})
+	childCid, child := sign(1000, []cid.Cid{middleCid})
+	le := Ledger{
+		Tip: childCid,
+		Headers: map[cid.Cid]header.Header{
+			parentCid: *parent,
+			middleCid: *middle,
+			childCid:  *child,
+		},
+	}
+	if le.Validate() == nil {
+		t.Error("Timestamp before an accepted header without a timestamp validated")
+	}
+}
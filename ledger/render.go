@@ -0,0 +1,227 @@
+package ledger
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+)
+
+// WriteGraph renders the ledger DAG as "dot", "mermaid", or "graphml". Accept edges
+// are solid and reject edges dashed, with visible blocks coloured differently from
+// pruned ones. The issuer function labels each header's issuer DID.
+func (ledger *Ledger) WriteGraph(w io.Writer, format string, issuer func(string) string) error {
+	table := ledger.MakeHeaderTable()
+	visible := ledger.Reachable()
+	switch format {
+	case "dot":
+		return table.writeDot(w, visible, issuer)
+	case "mermaid":
+		return table.writeMermaid(w, visible, issuer)
+	case "graphml":
+		return table.writeGraphML(w, visible, issuer)
+	default:
+		return fmt.Errorf("unsupported graph format: %v", format)
+	}
+}
+
+// nodes lists the table's nodes in index order.
+func (table *HeaderTable) nodes() []*HeaderNode {
+	nodes := make([]*HeaderNode, len(table.FromIndex))
+	for i, node := range table.FromIndex {
+		nodes[i] = node
+	}
+	return nodes
+}
+
+// edges calls the function for each accept and reject edge whose endpoints are in the table.
+func (table *HeaderTable) edges(f func(from *HeaderNode, to *HeaderNode, reject bool) error) error {
+	for _, node := range table.nodes() {
+		for _, acceptCid := range node.Header.Payload.Accept {
+			if parent, present := table.FromCid[acceptCid]; present {
+				if err := f(node, parent, false); err != nil {
+					return err
+				}
+			}
+		}
+		for _, rejectCid := range node.Header.Payload.Reject {
+			if rejected, present := table.FromCid[rejectCid]; present {
+				if err := f(node, rejected, true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func nodeStatus(visible map[cid.Cid]bool, node *HeaderNode) string {
+	if visible[node.HeaderCid] {
+		return "visible"
+	}
+	return "pruned"
+}
+
+func (table *HeaderTable) writeDot(w io.Writer, visible map[cid.Cid]bool, issuer func(string) string) error {
+	_, err := fmt.Fprint(w, "digraph ledger {\n  node [shape=box, style=filled];\n")
+	if err != nil {
+		return err
+	}
+	colors := map[string]string{"visible": "palegreen", "pruned": "lightpink"}
+	for _, node := range table.nodes() {
+		label := fmt.Sprintf("%v\nissuer: %v\nbody: %v", node.HeaderCid, issuer(node.Header.Issuer), node.Header.Payload.Body)
+		_, err = fmt.Fprintf(w, "  n%d [label=%q, fillcolor=%q];\n", node.Index, label, colors[nodeStatus(visible, node)])
+		if err != nil {
+			return err
+		}
+	}
+	err = table.edges(func(from *HeaderNode, to *HeaderNode, reject bool) error {
+		attributes := ""
+		if reject {
+			attributes = " [style=dashed, color=red]"
+		}
+		_, err := fmt.Fprintf(w, "  n%d -> n%d%v;\n", from.Index, to.Index, attributes)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, "}\n")
+	return err
+}
+
+func (table *HeaderTable) writeMermaid(w io.Writer, visible map[cid.Cid]bool, issuer func(string) string) error {
+	_, err := fmt.Fprint(w, "flowchart TB\n")
+	if err != nil {
+		return err
+	}
+	escape := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+	classes := make(map[string][]string)
+	for _, node := range table.nodes() {
+		label := fmt.Sprintf("%v<br/>issuer: %v<br/>body: %v", node.HeaderCid, escape.Replace(issuer(node.Header.Issuer)), node.Header.Payload.Body)
+		_, err = fmt.Fprintf(w, "  n%d[\"%v\"]\n", node.Index, label)
+		if err != nil {
+			return err
+		}
+		status := nodeStatus(visible, node)
+		classes[status] = append(classes[status], fmt.Sprintf("n%d", node.Index))
+	}
+	err = table.edges(func(from *HeaderNode, to *HeaderNode, reject bool) error {
+		arrow := "-->"
+		if reject {
+			arrow = "-.->|reject|"
+		}
+		_, err := fmt.Fprintf(w, "  n%d %v n%d\n", from.Index, arrow, to.Index)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, "  classDef visible fill:#98fb98\n  classDef pruned fill:#ffb6c1\n")
+	if err != nil {
+		return err
+	}
+	for _, status := range []string{"visible", "pruned"} {
+		if len(classes[status]) > 0 {
+			_, err = fmt.Fprintf(w, "  class %v %v\n", strings.Join(classes[status], ","), status)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (table *HeaderTable) writeGraphML(w io.Writer, visible map[cid.Cid]bool, issuer func(string) string) error {
+	doc := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "cid", For: "node", AttrName: "cid", AttrType: "string"},
+			{ID: "issuer", For: "node", AttrName: "issuer", AttrType: "string"},
+			{ID: "body", For: "node", AttrName: "body", AttrType: "string"},
+			{ID: "status", For: "node", AttrName: "status", AttrType: "string"},
+			{ID: "kind", For: "edge", AttrName: "kind", AttrType: "string"},
+		},
+		Graph: graphMLGraph{
+			ID:          "ledger",
+			EdgeDefault: "directed",
+		},
+	}
+	for _, node := range table.nodes() {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: fmt.Sprintf("n%d", node.Index),
+			Data: []graphMLData{
+				{Key: "cid", Value: node.HeaderCid.String()},
+				{Key: "issuer", Value: issuer(node.Header.Issuer)},
+				{Key: "body", Value: node.Header.Payload.Body.String()},
+				{Key: "status", Value: nodeStatus(visible, node)},
+			},
+		})
+	}
+	err := table.edges(func(from *HeaderNode, to *HeaderNode, reject bool) error {
+		kind := "accept"
+		if reject {
+			kind = "reject"
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: fmt.Sprintf("n%d", from.Index),
+			Target: fmt.Sprintf("n%d", to.Index),
+			Data:   []graphMLData{{Key: "kind", Value: kind}},
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(doc)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
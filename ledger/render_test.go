@@ -0,0 +1,67 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestWriteGraph(t *testing.T) {
+	hs := empty()
+	hs[c0] = *h0
+	hs[c1] = *h1
+	hs[c2] = *h2
+	hs[c4] = *h4
+	le := Ledger{
+		Tip:     c4,
+		Headers: hs,
+	}
+	issuer := func(did string) string { return did }
+
+	t.Run("DOT", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := le.WriteGraph(&buf, "dot", issuer); err != nil {
+			t.Fatal(err)
+		}
+		dot := buf.String()
+		if strings.Count(dot, "->") != 4 || strings.Count(dot, "style=dashed") != 1 {
+			t.Error("Incorrect DOT edges")
+		}
+		if strings.Count(dot, "lightpink") != 2 || strings.Count(dot, "palegreen") != 2 {
+			t.Error("Incorrect DOT colouring")
+		}
+	})
+
+	t.Run("Mermaid", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := le.WriteGraph(&buf, "mermaid", issuer); err != nil {
+			t.Fatal(err)
+		}
+		mermaid := buf.String()
+		if strings.Count(mermaid, " --> ") != 3 || strings.Count(mermaid, " -.->") != 1 {
+			t.Error("Incorrect Mermaid edges")
+		}
+	})
+
+	t.Run("GraphML", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := le.WriteGraph(&buf, "graphml", issuer); err != nil {
+			t.Fatal(err)
+		}
+		var doc graphML
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if len(doc.Graph.Nodes) != 4 || len(doc.Graph.Edges) != 4 {
+			t.Error("Incorrect GraphML nodes or edges")
+		}
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		var buf bytes.Buffer
+		if le.WriteGraph(&buf, "svg", issuer) == nil {
+			t.Error("Unsupported format accepted")
+		}
+	})
+}
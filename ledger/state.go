@@ -0,0 +1,94 @@
+package ledger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/functionally/nacatgunma/header"
+	"github.com/functionally/nacatgunma/rdf"
+)
+
+// AssembleState converts the JSON-LD bodies of the visible blocks, read from the
+// body folder, into the participant's state graph, ancestors first, resolving relative
+// IRIs against the bodies' CIDs. Blocks whose bodies are not JSON-LD are skipped.
+func (ledger *Ledger) AssembleState(bodyDir string) (*rdf.State, error) {
+	table := ledger.MakeHeaderTable()
+	order, err := table.TopologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+	visible := ledger.Reachable()
+	var state rdf.State
+	for _, hdrCid := range order {
+		hdr := ledger.Headers[hdrCid]
+		if !visible[hdrCid] || !rdf.IsRdfBody(hdr.Payload.SchemaURI, hdr.Payload.MediaType) {
+			continue
+		}
+		body, err := readBody(bodyDir, hdrCid, hdr)
+		if err != nil {
+			return nil, err
+		}
+		triples, err := rdf.BodyTriples(body, rdf.BodyBaseURI(hdr.Payload.Body))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON-LD in block %v: %w", hdrCid, err)
+		}
+		ancestors, err := table.Ancestors(hdrCid)
+		if err != nil {
+			return nil, err
+		}
+		state.Blocks = append(state.Blocks, rdf.BlockGraph{
+			Block:     hdrCid,
+			Issuer:    hdr.Issuer,
+			Triples:   triples,
+			Ancestors: ancestors,
+		})
+	}
+	return &state, nil
+}
+
+// ValidateBodies validates the JSON-LD bodies of the visible blocks, read from the body
+// folder, against the SHACL shapes for their schema URIs, resolving relative IRIs against
+// the bodies' CIDs. Blocks whose bodies are not JSON-LD, or whose schemas have no shapes
+// in the catalog, are skipped.
+func (ledger *Ledger) ValidateBodies(bodyDir string, catalog *rdf.ShapesCatalog) (map[cid.Cid]*rdf.Report, error) {
+	visible := ledger.Reachable()
+	reports := make(map[cid.Cid]*rdf.Report)
+	for _, hdrCid := range sortedCids(ledger.Headers) {
+		hdr := ledger.Headers[hdrCid]
+		if !visible[hdrCid] || !rdf.IsRdfBody(hdr.Payload.SchemaURI, hdr.Payload.MediaType) {
+			continue
+		}
+		shapes, err := catalog.Lookup(hdr.Payload.SchemaURI)
+		if err != nil {
+			return nil, err
+		} else if shapes == nil {
+			continue
+		}
+		body, err := readBody(bodyDir, hdrCid, hdr)
+		if err != nil {
+			return nil, err
+		}
+		report, err := shapes.ValidateBody(body, rdf.BodyBaseURI(hdr.Payload.Body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate body of block %v: %w", hdrCid, err)
+		}
+		reports[hdrCid] = report
+	}
+	return reports, nil
+}
+
+// readBody reads and decodes the JSON-LD body of a block from the body folder.
+func readBody(bodyDir string, hdrCid cid.Cid, hdr header.Header) (interface{}, error) {
+	bodyBytes, err := os.ReadFile(filepath.Join(bodyDir, hdr.Payload.Body.String()))
+	if err != nil {
+		return nil, err
+	}
+	body, err := rdf.DecodeBody(bodyBytes, hdr.Payload.MediaType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body of block %v: %w", hdrCid, err)
+	}
+	return body, nil
+}
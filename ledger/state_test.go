@@ -0,0 +1,385 @@
+package ledger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/functionally/nacatgunma/header"
+	"github.com/functionally/nacatgunma/ipfs"
+	"github.com/functionally/nacatgunma/key"
+	"github.com/functionally/nacatgunma/rdf"
+	"github.com/ipfs/go-cid"
+)
+
+func makeBodyHeader(t *testing.T, bodyDir string, doc interface{}, accept []cid.Cid, reject []cid.Cid) (cid.Cid, *header.Header) {
+	return makeSchemaBodyHeader(t, bodyDir, doc, rdf.JsonLdCborSchema, rdf.DagCborMediaType, accept, reject)
+}
+
+func makeSchemaBodyHeader(t *testing.T, bodyDir string, doc interface{}, schemaURI string, mediaType string, accept []cid.Cid, reject []cid.Cid) (cid.Cid, *header.Header) {
+	bodyBytes, err := ipfs.EncodeToDagCbor(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ipfs.CidV1(bodyBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(bodyDir, body.String()), bodyBytes, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := header.Payload{
+		Version:   1,
+		Body:      *body,
+		Accept:    accept,
+		Reject:    reject,
+		SchemaURI: schemaURI,
+		MediaType: mediaType,
+	}
+	ky, _ := key.GenerateKey(key.Ed25519)
+	hdr, _ := payload.Sign(ky)
+	hdrBytes, _ := hdr.Marshal()
+	hdrCid, _ := ipfs.CidV1(hdrBytes)
+	return *hdrCid, hdr
+}
+
+func TestAssembleState(t *testing.T) {
+	bodyDir := t.TempDir()
+	cA, hA := makeBodyHeader(t, bodyDir, map[string]interface{}{
+		"@id":                     "urn:example:thing",
+		"http://example.org/name": "first",
+	}, []cid.Cid{}, []cid.Cid{})
+	cB, hB := makeBodyHeader(t, bodyDir, map[string]interface{}{
+		"@context": map[string]interface{}{"name": "http://example.org/name"},
+		"@id":      "urn:example:thing",
+		"name":     "second",
+		"http://example.org/part": map[string]interface{}{
+			"http://example.org/size": float64(3),
+		},
+	}, []cid.Cid{cA}, []cid.Cid{})
+	cC, hC := makeBodyHeader(t, bodyDir, map[string]interface{}{
+		"@id":                     "urn:example:thing",
+		"http://example.org/name": "third",
+	}, []cid.Cid{cB}, []cid.Cid{cA})
+	hs := empty()
+	hs[cA] = *hA
+	hs[cB] = *hB
+	hs[cC] = *hC
+	le := Ledger{
+		Tip:     cC,
+		Headers: hs,
+	}
+	state, err := le.AssembleState(bodyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Blocks) != 2 || state.Blocks[0].Block != cB || state.Blocks[1].Block != cC {
+		t.Fatal("Incorrect visible blocks or order")
+	}
+	if len(state.Blocks[0].Triples) != 3 || len(state.Blocks[1].Triples) != 1 {
+		t.Error("Incorrect number of triples")
+	}
+	var buf bytes.Buffer
+	if err := state.WriteNquads(&buf); err != nil {
+		t.Fatal(err)
+	}
+	nquads := buf.String()
+	graphB := "<" + rdf.GraphName(hB.Issuer, cB) + ">"
+	if !strings.Contains(nquads, `<urn:example:thing> <http://example.org/name> "second" `+graphB+" .\n") {
+		t.Error("Missing quad in named graph")
+	}
+	if !strings.Contains(nquads, `"3"^^<http://www.w3.org/2001/XMLSchema#integer>`) {
+		t.Error("Incorrect integer literal")
+	}
+	if strings.Contains(nquads, `"first"`) {
+		t.Error("Rejected block in state")
+	}
+	buf.Reset()
+	if err := state.WriteTrig(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buf.String(), "{\n") != 2 {
+		t.Error("Incorrect TriG graphs")
+	}
+	buf.Reset()
+	if err := state.Write(&buf, rdf.RdfStarReification, "turtle"); err != nil {
+		t.Fatal(err)
+	}
+	annotation := `<urn:example:thing> <http://example.org/name> "third" {| prov:wasAttributedTo <` + hC.Issuer + `> ; prov:wasDerivedFrom <ipfs://` + cC.String() + `> |} .`
+	if !strings.Contains(buf.String(), annotation) {
+		t.Error("Missing RDF-star annotation")
+	}
+	buf.Reset()
+	if err := state.Write(&buf, rdf.ProvReification, "nquads"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buf.String(), "<http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement>") != 4 {
+		t.Error("Incorrect PROV-O reification")
+	}
+	if state.Write(&buf, rdf.NamedGraphReification, "turtle") == nil {
+		t.Error("Named graphs written as Turtle")
+	}
+}
+
+func TestAssembleStateRelativeIRIs(t *testing.T) {
+	bodyDir := t.TempDir()
+	cA, hA := makeBodyHeader(t, bodyDir, map[string]interface{}{
+		"@id": "#alice",
+		"http://example.org/knows": map[string]interface{}{
+			"@id": "#bob",
+		},
+	}, []cid.Cid{}, []cid.Cid{})
+	hs := empty()
+	hs[cA] = *hA
+	le := Ledger{
+		Tip:     cA,
+		Headers: hs,
+	}
+	state, err := le.AssembleState(bodyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Blocks) != 1 || len(state.Blocks[0].Triples) != 1 {
+		t.Fatal("Relative IRIs dropped from state")
+	}
+	base := rdf.BodyBaseURI(hA.Payload.Body)
+	triple := state.Blocks[0].Triples[0]
+	if triple.Subject.GetValue() != base+"#alice" || triple.Object.GetValue() != base+"#bob" {
+		t.Errorf("Relative IRIs not resolved against the body: %v %v", triple.Subject.GetValue(), triple.Object.GetValue())
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	bodyDir := t.TempDir()
+	cA, hA := makeBodyHeader(t, bodyDir, map[string]interface{}{
+		"@graph": []interface{}{
+			map[string]interface{}{
+				"@id":   "http://example.org/name",
+				"@type": "http://www.w3.org/2002/07/owl#FunctionalProperty",
+			},
+			map[string]interface{}{
+				"@id":                     "urn:example:thing",
+				"http://example.org/name": "first",
+				"http://example.org/tag":  "red",
+			},
+		},
+	}, []cid.Cid{}, []cid.Cid{})
+	cB, hB := makeBodyHeader(t, bodyDir, map[string]interface{}{
+		"@id":                     "urn:example:thing",
+		"http://example.org/name": "second",
+		"http://example.org/tag":  "blue",
+	}, []cid.Cid{cA}, []cid.Cid{})
+	hs := empty()
+	hs[cA] = *hA
+	hs[cB] = *hB
+	le := Ledger{
+		Tip:     cB,
+		Headers: hs,
+	}
+	state, err := le.AssembleState(bodyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	declared := state.FunctionalProperties()
+	if len(declared) != 1 || declared[0] != "http://example.org/name" {
+		t.Fatalf("Incorrect declared functional properties: %v", declared)
+	}
+	conflicts := state.Conflicts(declared)
+	if len(conflicts) != 1 {
+		t.Fatalf("Incorrect number of conflicts: %v", len(conflicts))
+	}
+	assertions := conflicts[0].Assertions
+	if len(assertions) != 2 || assertions[0].Object != `"first"` || assertions[0].Block != cA || assertions[1].Issuer != hB.Issuer {
+		t.Error("Incorrect conflicting assertions")
+	}
+	if len(state.Conflicts(append(declared, "http://example.org/tag"))) != 2 {
+		t.Error("Missing conflict for configured property")
+	}
+}
+
+func TestConflictAncestry(t *testing.T) {
+	bodyDir := t.TempDir()
+	doc := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"@id":                     "urn:example:thing",
+			"http://example.org/name": name,
+		}
+	}
+	cA, hA := makeBodyHeader(t, bodyDir, doc("first"), []cid.Cid{}, []cid.Cid{})
+	cB, hB := makeBodyHeader(t, bodyDir, doc("second"), []cid.Cid{cA}, []cid.Cid{})
+	cC, hC := makeBodyHeader(t, bodyDir, doc("third"), []cid.Cid{cA}, []cid.Cid{})
+	cD, hD := makeBodyHeader(t, bodyDir, map[string]interface{}{}, []cid.Cid{cB, cC}, []cid.Cid{})
+	hs := empty()
+	hs[cA] = *hA
+	hs[cB] = *hB
+	hs[cC] = *hC
+	hs[cD] = *hD
+	le := Ledger{
+		Tip:     cD,
+		Headers: hs,
+	}
+	state, err := le.AssembleState(bodyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conflicts := state.Conflicts([]string{"http://example.org/name"})
+	if len(conflicts) != 1 || len(conflicts[0].Assertions) != 3 {
+		t.Fatal("Incorrect conflicts")
+	}
+	for _, assertion := range conflicts[0].Assertions {
+		var supersedes, concurrent []cid.Cid
+		switch assertion.Block {
+		case cB:
+			supersedes, concurrent = []cid.Cid{cA}, []cid.Cid{cC}
+		case cC:
+			supersedes, concurrent = []cid.Cid{cA}, []cid.Cid{cB}
+		}
+		if !assertEqual(assertion.Supersedes, supersedes) || !assertEqual(assertion.Concurrent, concurrent) {
+			t.Errorf("Incorrect ancestry for block %v: supersedes %v, concurrent with %v", assertion.Block, assertion.Supersedes, assertion.Concurrent)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	bodyDir := t.TempDir()
+	doc := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"@id":                     "urn:example:thing",
+			"http://example.org/name": name,
+		}
+	}
+	cA, hA := makeBodyHeader(t, bodyDir, doc("first"), []cid.Cid{}, []cid.Cid{})
+	cB, hB := makeBodyHeader(t, bodyDir, doc("second"), []cid.Cid{cA}, []cid.Cid{})
+	cC, hC := makeBodyHeader(t, bodyDir, doc("first"), []cid.Cid{cB}, []cid.Cid{})
+	hs := empty()
+	hs[cA] = *hA
+	hs[cB] = *hB
+	hs[cC] = *hC
+	le := Ledger{
+		Tip:     cC,
+		Headers: hs,
+	}
+	state, err := le.AssembleState(bodyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	properties := []string{"http://example.org/name"}
+	trust := rdf.Trust{hA.Issuer: 0.5, hB.Issuer: 0.9, hC.Issuer: 0.1}
+	for _, test := range []struct {
+		policy string
+		winner cid.Cid
+		kept   int
+	}{
+		{rdf.LastWriterPolicy, cC, 2},
+		{rdf.HighestTrustPolicy, cB, 1},
+		{rdf.MajorityPolicy, cC, 2},
+	} {
+		resolved, resolutions, err := state.Resolve(properties, test.policy, trust)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resolutions) != 1 || resolutions[0].Winner.Block != test.winner {
+			t.Errorf("Incorrect winner for %v", test.policy)
+		}
+		kept := 0
+		for _, block := range resolved.Blocks {
+			kept += len(block.Triples)
+		}
+		if kept != test.kept {
+			t.Errorf("Incorrect resolved graph for %v: %v triples", test.policy, kept)
+		}
+	}
+	if _, _, err := state.Resolve(properties, "coin-toss", trust); err == nil {
+		t.Error("Unsupported policy accepted")
+	}
+}
+
+func TestValidateBodies(t *testing.T) {
+	bodyDir := t.TempDir()
+	cA, hA := makeBodyHeader(t, bodyDir, map[string]interface{}{
+		"@id":                     "urn:example:thing",
+		"http://example.org/name": "named",
+	}, []cid.Cid{}, []cid.Cid{})
+	cB, hB := makeBodyHeader(t, bodyDir, map[string]interface{}{
+		"@id":                     "urn:example:thing",
+		"http://example.org/size": float64(3),
+	}, []cid.Cid{cA}, []cid.Cid{})
+	hs := empty()
+	hs[cA] = *hA
+	hs[cB] = *hB
+	le := Ledger{
+		Tip:     cB,
+		Headers: hs,
+	}
+	shapesFile := filepath.Join(t.TempDir(), "shapes.ttl")
+	err := os.WriteFile(shapesFile, []byte(`@prefix sh: <http://www.w3.org/ns/shacl#> .
+<urn:example:shape> sh:targetNode <urn:example:thing> ;
+  sh:property [ sh:path <http://example.org/name> ; sh:minCount 1 ] .
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shapes, err := rdf.ReadShapes(shapesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports, err := le.ValidateBodies(bodyDir, rdf.NewShapesCatalog(shapes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 || !reports[cA].Conforms || reports[cB].Conforms {
+		t.Errorf("Incorrect validation reports: %v", reports)
+	}
+	mapFile := filepath.Join(filepath.Dir(shapesFile), "shapes.json")
+	err = os.WriteFile(mapFile, []byte(`{"https://example.org/other": "shapes.ttl"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog, err := rdf.ReadShapesCatalog(mapFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports, err = le.ValidateBodies(bodyDir, catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 0 {
+		t.Error("Validated bodies with unmapped schema")
+	}
+	person := map[string]interface{}{
+		"@id":                     "urn:example:thing",
+		"http://example.org/size": float64(4),
+	}
+	cC, hC := makeSchemaBodyHeader(t, bodyDir, person, "https://example.org/other", rdf.DagCborJsonLdMediaType, []cid.Cid{cB}, []cid.Cid{})
+	cD, hD := makeSchemaBodyHeader(t, bodyDir, person, "https://example.org/other", rdf.DagCborMediaType, []cid.Cid{cC}, []cid.Cid{})
+	hs[cC] = *hC
+	hs[cD] = *hD
+	le.Tip = cD
+	reports, err = le.ValidateBodies(bodyDir, catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 || reports[cC] == nil || reports[cC].Conforms {
+		t.Errorf("Incorrect validation reports for mapped schema: %v", reports)
+	}
+	cE, hE := makeBodyHeader(t, bodyDir, map[string]interface{}{
+		"@id":                     "urn:example:thing",
+		"http://example.org/name": "named",
+	}, []cid.Cid{cA}, []cid.Cid{cB})
+	hs[cE] = *hE
+	le.Tip = cE
+	err = os.Remove(filepath.Join(bodyDir, hB.Payload.Body.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports, err = le.ValidateBodies(bodyDir, rdf.NewShapesCatalog(shapes))
+	if err != nil {
+		t.Fatalf("Failed on the missing body of a rejected block: %v", err)
+	}
+	if len(reports) != 2 || !reports[cA].Conforms || !reports[cE].Conforms {
+		t.Errorf("Incorrect validation reports for visible blocks: %v", reports)
+	}
+}
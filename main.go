@@ -10,9 +10,12 @@ import (
 )
 
 func main() {
+	resolverFlags, configureResolver := cmd.ResolverFlags()
 	app := &cli.App{
-		Name:  "nacatgunma",
-		Usage: "Manage the Nacatgunma blockchain.",
+		Name:   "nacatgunma",
+		Usage:  "Manage the Nacatgunma blockchain.",
+		Flags:  resolverFlags,
+		Before: configureResolver,
 		Commands: []*cli.Command{
 			cmd.BodyCmds(),
 			cmd.CardanoCmds(),
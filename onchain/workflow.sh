@@ -32,10 +32,8 @@ case "$TYPE" in
     BODY=
     ### END CUSTOMIZATION ###
     BODY_TTL="$BODY.ttl"
-    BODY_NQ="$BODY.nq"
     BODY_CBOR="$BODY.cbor"
-    rapper -q -i turtle -o nquads "$BODY_TTL" "$BODY_IRI" > "$BODY_NQ"
-    BODY_CID=$(nacatgunma body rdf --base-uri "$BODY_IRI" --rdf-file "$BODY_NQ" --body-file "$BODY_CBOR")
+    BODY_CID=$(nacatgunma body rdf --base-uri "$BODY_IRI" --rdf-file "$BODY_TTL" --body-file "$BODY_CBOR")
     ipfs dag put --input-codec dag-cbor --store-codec dag-cbor --pin=false "$BODY_CBOR"
     MEDIA_TYPE=application/vnd.ipld.dag-cbor
     SCHEMA_URI=https://w3c.github.io/json-ld-cbor/
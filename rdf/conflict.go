@@ -0,0 +1,126 @@
+package rdf
+
+import (
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"github.com/piprate/json-gold/ld"
+)
+
+const (
+	owlFunctionalProperty = "http://www.w3.org/2002/07/owl#FunctionalProperty"
+	rdfType               = rdfNamespace + "type"
+)
+
+// Assertion records a block asserting an object for a subject and property. Within a
+// conflict, the assertion supersedes the other blocks of differing assertions that are
+// its ancestors, and is concurrent with those that are neither its ancestors nor its
+// descendants. WithinBlock marks an assertion whose own block also asserts a differing
+// object, which no ancestry can settle.
+type Assertion struct {
+	Object      string
+	Block       cid.Cid
+	Issuer      string
+	Supersedes  []cid.Cid `json:",omitempty"`
+	Concurrent  []cid.Cid `json:",omitempty"`
+	WithinBlock bool      `json:",omitempty"`
+}
+
+// Conflict lists the assertions of a functional property for a subject when they
+// do not all agree on the object. Terms are written as in N-Triples.
+type Conflict struct {
+	Subject    string
+	Property   string
+	Assertions []Assertion
+}
+
+// FunctionalProperties lists the properties that the state declares to be owl:FunctionalProperty.
+func (state *State) FunctionalProperties() []string {
+	found := make(map[string]bool)
+	for _, block := range state.Blocks {
+		for _, triple := range block.Triples {
+			if ld.IsIRI(triple.Subject) && triple.Predicate.GetValue() == rdfType && ld.IsIRI(triple.Object) && triple.Object.GetValue() == owlFunctionalProperty {
+				found[triple.Subject.GetValue()] = true
+			}
+		}
+	}
+	var properties []string
+	for property := range found {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+	return properties
+}
+
+// Assertions groups the assertions of the given properties by subject and property.
+func (state *State) Assertions(properties []string) map[[2]string][]Assertion {
+	functional := make(map[string]bool)
+	for _, property := range properties {
+		functional[property] = true
+	}
+	assertions := make(map[[2]string][]Assertion)
+	for i, block := range state.Blocks {
+		for _, triple := range block.Triples {
+			if !ld.IsIRI(triple.Predicate) || !functional[triple.Predicate.GetValue()] {
+				continue
+			}
+			k := [2]string{state.term(i, triple.Subject), formatIRI(triple.Predicate.GetValue())}
+			assertions[k] = append(assertions[k], Assertion{
+				Object: state.term(i, triple.Object),
+				Block:  block.Block,
+				Issuer: block.Issuer,
+			})
+		}
+	}
+	return assertions
+}
+
+// Conflicts finds the subjects with differing objects for any of the functional properties.
+func (state *State) Conflicts(properties []string) []Conflict {
+	conflicts := []Conflict{}
+	for k, assertions := range state.Assertions(properties) {
+		objects := make(map[string]bool)
+		for _, assertion := range assertions {
+			objects[assertion.Object] = true
+		}
+		if len(objects) > 1 {
+			state.relate(assertions)
+			conflicts = append(conflicts, Conflict{
+				Subject:    k[0],
+				Property:   k[1],
+				Assertions: assertions,
+			})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Subject != conflicts[j].Subject {
+			return conflicts[i].Subject < conflicts[j].Subject
+		}
+		return conflicts[i].Property < conflicts[j].Property
+	})
+	return conflicts
+}
+
+// relate records the ancestry between the blocks of assertions with differing objects.
+func (state *State) relate(assertions []Assertion) {
+	ancestors := make(map[cid.Cid]map[cid.Cid]bool)
+	for _, block := range state.Blocks {
+		ancestors[block.Block] = block.Ancestors
+	}
+	for i := range assertions {
+		for _, other := range assertions {
+			if other.Object == assertions[i].Object {
+				continue
+			}
+			if other.Block == assertions[i].Block {
+				assertions[i].WithinBlock = true
+				continue
+			}
+			if ancestors[assertions[i].Block][other.Block] {
+				assertions[i].Supersedes = append(assertions[i].Supersedes, other.Block)
+			} else if !ancestors[other.Block][assertions[i].Block] {
+				assertions[i].Concurrent = append(assertions[i].Concurrent, other.Block)
+			}
+		}
+	}
+}
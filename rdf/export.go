@@ -0,0 +1,415 @@
+package rdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/piprate/json-gold/ld"
+)
+
+// The formats for exporting a JSON-LD body as RDF.
+const (
+	TurtleExport   = "turtle"
+	TrigExport     = "trig"
+	NquadsExport   = "nquads"
+	ExpandedExport = "jsonld-expanded"
+)
+
+// WriteBody serializes a decoded JSON-LD body, expanded with its embedded context and
+// the base URI, as Turtle, TriG, N-Quads, or expanded JSON-LD. Turtle and TriG use
+// prefixes derived from the namespaces of the terms in the context. Relative IRIs that
+// the base URI does not resolve are an error, since RDF cannot represent them.
+func WriteBody(w io.Writer, doc interface{}, baseURI string, format string) error {
+	generic, err := normalizeJson(doc)
+	if err != nil {
+		return err
+	}
+	proc := ld.NewJsonLdProcessor()
+	options := ld.NewJsonLdOptions(baseURI)
+	expanded, err := proc.Expand(generic, options)
+	if err != nil {
+		return err
+	}
+	if iri, found := findRelativeIRI(expanded); found {
+		return fmt.Errorf("body has the relative IRI %q, which needs an absolute base URI", iri)
+	}
+	if format == ExpandedExport {
+		jsonBytes, err := json.MarshalIndent(expanded, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(jsonBytes))
+		return err
+	}
+	result, err := proc.ToRDF(expanded, options)
+	if err != nil {
+		return err
+	}
+	dataset, okay := result.(*ld.RDFDataset)
+	if !okay {
+		return fmt.Errorf("unexpected RDF conversion result: %T", result)
+	}
+	switch format {
+	case NquadsExport:
+		return writeDatasetNquads(w, dataset)
+	case TurtleExport, TrigExport:
+		graphNames := datasetGraphNames(dataset)
+		if format == TurtleExport && len(graphNames) > 1 {
+			return fmt.Errorf("body has named graphs, which need %v or %v", TrigExport, NquadsExport)
+		}
+		return writeDatasetTurtle(w, dataset, graphNames, ContextPrefixes(generic))
+	default:
+		return fmt.Errorf("unsupported export format: %v", format)
+	}
+}
+
+// findRelativeIRI looks for a node, type, or datatype IRI in an expanded document that
+// is neither absolute nor a blank node, and so would be dropped when converting to RDF.
+func findRelativeIRI(expanded interface{}) (string, bool) {
+	switch v := expanded.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if iri, found := findRelativeIRI(item); found {
+				return iri, true
+			}
+		}
+	case map[string]interface{}:
+		if _, isValue := v["@value"]; isValue {
+			if datatype, okay := v["@type"].(string); okay && !ld.IsAbsoluteIri(datatype) {
+				return datatype, true
+			}
+			return "", false
+		}
+		for key, value := range v {
+			switch key {
+			case "@id":
+				if iri, okay := value.(string); okay && !ld.IsAbsoluteIri(iri) && !strings.HasPrefix(iri, blankNodePrefix) {
+					return iri, true
+				}
+			case "@type":
+				types, _ := value.([]interface{})
+				for _, t := range types {
+					if iri, okay := t.(string); okay && !ld.IsAbsoluteIri(iri) && !strings.HasPrefix(iri, blankNodePrefix) {
+						return iri, true
+					}
+				}
+			default:
+				if iri, found := findRelativeIRI(value); found {
+					return iri, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// datasetGraphNames lists the non-empty graphs of the dataset, the default graph first.
+func datasetGraphNames(dataset *ld.RDFDataset) []string {
+	var graphNames []string
+	for graphName, triples := range dataset.Graphs {
+		if graphName != defaultGraph && len(triples) > 0 {
+			graphNames = append(graphNames, graphName)
+		}
+	}
+	sort.Strings(graphNames)
+	return append([]string{defaultGraph}, graphNames...)
+}
+
+func writeDatasetNquads(w io.Writer, dataset *ld.RDFDataset) error {
+	var lines []string
+	for graphName, triples := range dataset.Graphs {
+		graph := ""
+		if graphName != defaultGraph {
+			graph = " " + formatGraphName(graphName)
+		}
+		for _, triple := range triples {
+			lines = append(lines, tripleKey(triple)+graph+" .\n")
+		}
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		_, err := fmt.Fprint(w, line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatGraphName(graphName string) string {
+	if strings.HasPrefix(graphName, blankNodePrefix) {
+		return graphName
+	}
+	return formatIRI(graphName)
+}
+
+// ContextPrefixes derives Turtle prefixes from the terms in a JSON-LD document's context.
+// Terms for namespaces ending in "/" or "#" keep their names, and the namespaces of
+// other terms receive names taken from their final path segment or host.
+func ContextPrefixes(doc interface{}) map[string]string {
+	definitions := make(map[string]string)
+	collectTerms(doc, definitions)
+	var terms []string
+	for term := range definitions {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	prefixes := make(map[string]string)
+	namespaces := make(map[string]bool)
+	for _, term := range terms {
+		iri := definitions[term]
+		if strings.HasSuffix(iri, "/") || strings.HasSuffix(iri, "#") {
+			if isPrefixName(term) {
+				prefixes[term] = iri
+				namespaces[iri] = true
+			}
+		}
+	}
+	for _, term := range terms {
+		namespace, _ := splitIRI(definitions[term])
+		if namespace == "" || namespaces[namespace] {
+			continue
+		}
+		name := namespaceName(namespace)
+		unique := name
+		for i := 1; prefixes[unique] != ""; i++ {
+			unique = fmt.Sprintf("%v%v", name, i)
+		}
+		prefixes[unique] = namespace
+		namespaces[namespace] = true
+	}
+	return prefixes
+}
+
+func collectTerms(doc interface{}, definitions map[string]string) {
+	switch v := doc.(type) {
+	case []interface{}:
+		for _, item := range v {
+			collectTerms(item, definitions)
+		}
+	case map[string]interface{}:
+		context, okay := v["@context"]
+		if !okay {
+			return
+		}
+		var contexts []interface{}
+		if list, isList := context.([]interface{}); isList {
+			contexts = list
+		} else {
+			contexts = []interface{}{context}
+		}
+		for _, context := range contexts {
+			terms, okay := context.(map[string]interface{})
+			if !okay {
+				continue
+			}
+			for term, definition := range terms {
+				if strings.HasPrefix(term, "@") {
+					continue
+				}
+				var iri string
+				switch d := definition.(type) {
+				case string:
+					iri = d
+				case map[string]interface{}:
+					iri, _ = d["@id"].(string)
+				}
+				if ld.IsAbsoluteIri(iri) && !strings.HasPrefix(iri, "@") {
+					definitions[term] = iri
+				}
+			}
+		}
+	}
+}
+
+// splitIRI splits an IRI after its last "#" or "/" into a namespace and a local name.
+func splitIRI(iri string) (string, string) {
+	i := strings.LastIndexAny(iri, "#/")
+	if i < 0 || strings.HasSuffix(iri[:i+1], "://") {
+		return "", iri
+	}
+	return iri[:i+1], iri[i+1:]
+}
+
+func namespaceName(namespace string) string {
+	trimmed := strings.TrimRight(namespace, "/#")
+	name := trimmed[strings.LastIndexAny(trimmed, "/:")+1:]
+	if strings.Contains(name, ".") {
+		for _, part := range strings.Split(name, ".") {
+			if part != "www" {
+				name = part
+				break
+			}
+		}
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' {
+			return r
+		}
+		return -1
+	}, name)
+	if !isPrefixName(name) {
+		name = "ns" + name
+	}
+	return name
+}
+
+func isPrefixName(name string) bool {
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func isLocalName(name string) bool {
+	if name == "" || name[0] == '-' {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// The lexical forms that Turtle can write without a datatype.
+var bareLiterals = map[string]*regexp.Regexp{
+	ld.XSDInteger: regexp.MustCompile(`^[+-]?[0-9]+$`),
+	ld.XSDDecimal: regexp.MustCompile(`^[+-]?[0-9]*\.[0-9]+$`),
+	ld.XSDDouble:  regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)[eE][+-]?[0-9]+$`),
+	ld.XSDBoolean: regexp.MustCompile(`^(true|false)$`),
+}
+
+// turtleWriter abbreviates terms with prefixes, recording which prefixes are used.
+type turtleWriter struct {
+	namespaces map[string]string
+	used       map[string]bool
+}
+
+func (tw *turtleWriter) iri(iri string) string {
+	namespace, local := splitIRI(iri)
+	if prefix, okay := tw.namespaces[namespace]; okay && isLocalName(local) {
+		tw.used[prefix] = true
+		return prefix + ":" + local
+	}
+	return formatIRI(iri)
+}
+
+func (tw *turtleWriter) term(node ld.Node) string {
+	switch n := node.(type) {
+	case *ld.IRI:
+		return tw.iri(n.Value)
+	case *ld.Literal:
+		if pattern, okay := bareLiterals[n.Datatype]; okay && pattern.MatchString(n.Value) {
+			return n.Value
+		}
+		if n.Datatype != "" && n.Datatype != xsdString && n.Datatype != rdfLangString {
+			return `"` + escapeLiteral(n.Value) + `"^^` + tw.iri(n.Datatype)
+		}
+		return formatTerm(n)
+	default:
+		return formatTerm(node)
+	}
+}
+
+func writeDatasetTurtle(w io.Writer, dataset *ld.RDFDataset, graphNames []string, prefixes map[string]string) error {
+	tw := turtleWriter{
+		namespaces: make(map[string]string),
+		used:       make(map[string]bool),
+	}
+	for prefix, namespace := range prefixes {
+		if existing, okay := tw.namespaces[namespace]; !okay || prefix < existing {
+			tw.namespaces[namespace] = prefix
+		}
+	}
+	var body bytes.Buffer
+	for _, graphName := range graphNames {
+		indent := ""
+		if graphName != defaultGraph {
+			indent = "  "
+			fmt.Fprintf(&body, "\n%v {\n", tw.term(graphTerm(graphName)))
+		}
+		tw.writeTriples(&body, dataset.Graphs[graphName], indent)
+		if graphName != defaultGraph {
+			fmt.Fprint(&body, "}\n")
+		}
+	}
+	var names []string
+	for prefix := range tw.used {
+		names = append(names, prefix)
+	}
+	sort.Strings(names)
+	for _, prefix := range names {
+		_, err := fmt.Fprintf(w, "@prefix %v: %v .\n", prefix, formatIRI(prefixes[prefix]))
+		if err != nil {
+			return err
+		}
+	}
+	if len(names) > 0 && body.Len() > 0 && body.Bytes()[0] != '\n' {
+		_, err := fmt.Fprintln(w)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := body.WriteTo(w)
+	return err
+}
+
+func graphTerm(graphName string) ld.Node {
+	if strings.HasPrefix(graphName, blankNodePrefix) {
+		return ld.NewBlankNode(graphName)
+	}
+	return ld.NewIRI(graphName)
+}
+
+// writeTriples writes the triples grouped by subject and predicate, with types first.
+func (tw *turtleWriter) writeTriples(w io.Writer, triples []*ld.Quad, indent string) {
+	sorted := append([]*ld.Quad{}, triples...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		si, sj := formatTerm(sorted[i].Subject), formatTerm(sorted[j].Subject)
+		if si != sj {
+			return si < sj
+		}
+		ti, tj := sorted[i].Predicate.GetValue() == ld.RDFType, sorted[j].Predicate.GetValue() == ld.RDFType
+		if ti != tj {
+			return ti
+		}
+		return tripleKey(sorted[i]) < tripleKey(sorted[j])
+	})
+	for i, triple := range sorted {
+		sameSubject := i > 0 && sorted[i-1].Subject.Equal(triple.Subject)
+		samePredicate := sameSubject && sorted[i-1].Predicate.Equal(triple.Predicate)
+		if samePredicate {
+			fmt.Fprintf(w, ", %v", tw.term(triple.Object))
+			continue
+		}
+		if sameSubject {
+			fmt.Fprintf(w, " ;\n%v    ", indent)
+		} else {
+			if i > 0 {
+				fmt.Fprint(w, " .\n")
+			}
+			fmt.Fprintf(w, "%v%v ", indent, tw.term(triple.Subject))
+		}
+		predicate := "a"
+		if triple.Predicate.GetValue() != ld.RDFType {
+			predicate = tw.term(triple.Predicate)
+		}
+		fmt.Fprintf(w, "%v %v", predicate, tw.term(triple.Object))
+	}
+	if len(sorted) > 0 {
+		fmt.Fprint(w, " .\n")
+	}
+}
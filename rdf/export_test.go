@@ -0,0 +1,131 @@
+package rdf
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/functionally/nacatgunma/ipfs"
+)
+
+const testTurtle = `@prefix s: <http://schema.org/> .
+<#alice> a s:Person ; s:name "Alice"@en, "Alicia" ; s:age 42 ;
+  s:knows [ s:name "Bob" ] ; s:likes ( "tea" "coffee" ) .
+`
+
+func TestWriteBody(t *testing.T) {
+	base := "http://example.org/p"
+	encode := func(filename string) []byte {
+		doc, err := ReadRdfWithOptions(filename, ReadOptions{
+			BaseURI:      base,
+			Canonicalize: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		bodyBytes, err := ipfs.EncodeToDagCbor(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bodyBytes
+	}
+	original := encode(writeTestFile(t, "original.ttl", testTurtle))
+	body, err := ipfs.DecodeFromDagCbor(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodyCid, err := ipfs.CidV1(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The IRIs resolved against the base URI when reading stay absolute in the body, so
+	// exporting against the body's CID, as rdf-export does by default, preserves them.
+	for format, extension := range map[string]string{TurtleExport: ".ttl", TrigExport: ".trig", NquadsExport: ".nq"} {
+		var exported bytes.Buffer
+		err = WriteBody(&exported, body, BodyBaseURI(*bodyCid), format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if format == TurtleExport && !strings.HasPrefix(exported.String(), "@prefix schema: <http://schema.org/> .\n\n<http://example.org/p#alice> a schema:Person ;\n") {
+			t.Errorf("Unexpected Turtle:\n%v", exported.String())
+		}
+		roundTrip := encode(writeTestFile(t, "exported"+extension, exported.String()))
+		if !reflect.DeepEqual(original, roundTrip) {
+			t.Errorf("Body differs after round trip through %v:\n%v", format, exported.String())
+		}
+	}
+	var expanded bytes.Buffer
+	err = WriteBody(&expanded, body, base, ExpandedExport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(expanded.String(), `"@id": "http://example.org/p#alice"`) {
+		t.Errorf("Relative IRI not expanded:\n%v", expanded.String())
+	}
+	relative, err := ReadRdfWithOptions(writeTestFile(t, "relative.ttl", testTurtle), ReadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{TurtleExport, NquadsExport, ExpandedExport} {
+		var unresolved bytes.Buffer
+		err = WriteBody(&unresolved, relative, "", format)
+		if err == nil || !strings.Contains(err.Error(), `"#alice"`) {
+			t.Errorf("Relative IRI without a base URI exported as %v: %v\n%v", format, err, unresolved.String())
+		}
+	}
+	var absolute bytes.Buffer
+	err = WriteBody(&absolute, map[string]interface{}{
+		"@id":                    "http://example.org/p#bob",
+		"http://schema.org/name": "Bob",
+	}, "", NquadsExport)
+	if err != nil || absolute.String() != "<http://example.org/p#bob> <http://schema.org/name> \"Bob\" .\n" {
+		t.Errorf("Absolute IRIs not exported without a base URI: %v\n%v", err, absolute.String())
+	}
+}
+
+func TestWriteBodyNamedGraphs(t *testing.T) {
+	body := map[string]interface{}{
+		"@id":      "http://example.org/g",
+		"@context": map[string]interface{}{"name": "http://schema.org/name"},
+		"@graph":   []interface{}{map[string]interface{}{"@id": "http://example.org/x", "name": "X"}},
+	}
+	var output bytes.Buffer
+	if err := WriteBody(&output, body, "", TurtleExport); err == nil {
+		t.Error("Named graph written as Turtle")
+	}
+	err := WriteBody(&output, body, "", TrigExport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "@prefix schema: <http://schema.org/> .\n\n<http://example.org/g> {\n  <http://example.org/x> schema:name \"X\" .\n}\n"
+	if output.String() != expected {
+		t.Errorf("Incorrect TriG:\n%v", output.String())
+	}
+}
+
+func TestContextPrefixes(t *testing.T) {
+	prefixes := ContextPrefixes(map[string]interface{}{
+		"@context": []interface{}{
+			"https://example.org/remote.jsonld",
+			map[string]interface{}{
+				"@vocab": "http://example.org/vocab#",
+				"dc":     "http://purl.org/dc/terms/",
+				"title":  "http://purl.org/dc/terms/title",
+				"name":   map[string]interface{}{"@id": "http://schema.org/name"},
+				"label":  "http://www.w3.org/2000/01/rdf-schema#label",
+				"other":  "http://example.org/schema/x",
+				"opaque": "urn:ex:opaque",
+			},
+		},
+	})
+	expected := map[string]string{
+		"dc":         "http://purl.org/dc/terms/",
+		"schema":     "http://schema.org/",
+		"schema1":    "http://example.org/schema/",
+		"rdf-schema": "http://www.w3.org/2000/01/rdf-schema#",
+	}
+	if !reflect.DeepEqual(prefixes, expected) {
+		t.Errorf("Incorrect prefixes: %v", prefixes)
+	}
+}
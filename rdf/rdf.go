@@ -2,8 +2,12 @@ package rdf
 
 import (
 	"bufio"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/cayleygraph/quad"
@@ -13,53 +17,180 @@ import (
 )
 
 func ReadRdf(filename string, baseURI string, format string) (interface{}, error) {
+	return ReadRdfWithOptions(filename, ReadOptions{
+		BaseURI: baseURI,
+		Format:  format,
+	})
+}
+
+// ReadOptions controls how RDF is read as compacted JSON-LD.
+type ReadOptions struct {
+	// The base URI against which relative IRIs are resolved, leaving them absolute in the body.
+	BaseURI string
+	// The MIME type or name of the RDF format, or empty to detect it from the filename.
+	Format string
+	// The context for compaction, or nil to generate one from the predicates.
+	Context map[string]interface{}
+	// Canonicalize the dataset with RDFC-1.0 (URDNA2015), so that the blank node labels
+	// and statement order do not depend on how the RDF was written.
+	Canonicalize bool
+}
+
+// ReadRdfWithOptions reads RDF as compacted JSON-LD.
+func ReadRdfWithOptions(filename string, opts ReadOptions) (interface{}, error) {
 	dataset, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
+	format, err := DetectFormat(filename, opts.Format)
+	if err != nil {
+		return nil, err
+	}
 	proc := ld.NewJsonLdProcessor()
+	options := ld.NewJsonLdOptions(opts.BaseURI)
+	var expandedDoc interface{}
+	if format == nquadsFormat {
+		options.Format = format
+		expandedDoc, err = proc.FromRDF(dataset, options)
+	} else {
+		var parsed *ld.RDFDataset
+		parsed, err = ParseTurtle(dataset, opts.BaseURI, format == trigFormat)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v: %w", format, err)
+		}
+		expandedDoc, err = ld.NewJsonLdApi().FromRDF(parsed, options)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.Canonicalize {
+		expandedDoc, err = canonicalize(proc, expandedDoc, opts.BaseURI)
+		if err != nil {
+			return nil, err
+		}
+	}
+	context := opts.Context
+	if context == nil {
+		context = generateContext(expandedDoc)
+	}
+	// Compacting against the base URI would make the IRIs relative again, and a body's
+	// relative IRIs are read against its CID.
+	return proc.Compact(expandedDoc, context, ld.NewJsonLdOptions(""))
+}
+
+// canonicalize relabels the blank nodes of an expanded document and orders its
+// statements according to RDFC-1.0 (URDNA2015).
+func canonicalize(proc *ld.JsonLdProcessor, expandedDoc interface{}, baseURI string) (interface{}, error) {
 	options := ld.NewJsonLdOptions(baseURI)
-	options.Format = format
-	expandedDoc, err := proc.FromRDF(dataset, options)
+	options.Algorithm = ld.AlgorithmURDNA2015
+	options.Format = nquadsFormat
+	canonical, err := proc.Normalize(expandedDoc, options)
 	if err != nil {
 		return nil, err
 	}
-	context := generateContext(expandedDoc)
-	return proc.Compact(expandedDoc, context, options)
+	options = ld.NewJsonLdOptions(baseURI)
+	options.Format = nquadsFormat
+	return proc.FromRDF(canonical, options)
 }
 
+// ReadContext reads a JSON-LD context file, either a document with an "@context" or the context itself.
+func ReadContext(filename string) (map[string]interface{}, error) {
+	contextBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var context map[string]interface{}
+	err = json.Unmarshal(contextBytes, &context)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON-LD context: %w", err)
+	}
+	if inner, okay := context["@context"]; okay {
+		context, okay = inner.(map[string]interface{})
+		if !okay {
+			return nil, fmt.Errorf("JSON-LD context is not an object")
+		}
+	}
+	return context, nil
+}
+
+// generateContext assigns a short term to each predicate IRI. The IRIs are taken in
+// sorted order and each receives the shortest unused prefix, of at least five
+// characters, of its last path segment or fragment, so the same predicates always
+// yield the same context.
 func generateContext(doc interface{}) map[string]interface{} {
+	found := make(map[string]bool)
+	scanForPredicates(doc, found)
+	var iris []string
+	for iri := range found {
+		iris = append(iris, iri)
+	}
+	sort.Strings(iris)
 	ctx := make(map[string]interface{})
-	scanForPredicates(doc, ctx, make(map[string]bool))
+	for _, iri := range iris {
+		short := shorten(iri)
+		if short == "" || strings.ContainsAny(short, ":@") {
+			continue
+		}
+		for i := min(5, len(short)); i <= len(short); i++ {
+			if _, exists := ctx[short[:i]]; !exists {
+				ctx[short[:i]] = iri
+				break
+			}
+		}
+	}
 	return ctx
 }
 
-func scanForPredicates(value interface{}, ctx map[string]interface{}, fnd map[string]bool) {
+func scanForPredicates(value interface{}, found map[string]bool) {
 	switch v := value.(type) {
 	case map[string]interface{}:
 		for k, val := range v {
-			if _, exists := fnd[k]; exists {
-				break
-			}
 			if isIRI(k) {
-				short := shorten(k)
-				if _, exists := ctx[short]; !exists {
-					for i := min(5, len(short)); i <= len(short); i++ {
-						if _, exists = ctx[short[:i]]; !exists {
-							fnd[k] = true
-							ctx[short[:i]] = k
-							break
-						}
-					}
-				}
+				found[k] = true
 			}
-			scanForPredicates(val, ctx, fnd)
+			scanForPredicates(val, found)
 		}
 	case []interface{}:
 		for _, item := range v {
-			scanForPredicates(item, ctx, fnd)
+			scanForPredicates(item, found)
+		}
+	}
+}
+
+const (
+	nquadsFormat   = "application/n-quads"
+	ntriplesFormat = "application/n-triples"
+	turtleFormat   = "text/turtle"
+	trigFormat     = "application/trig"
+)
+
+// DetectFormat returns the MIME type for a format name or MIME type, or, if the format
+// is empty, for the extension of the filename, defaulting to N-Quads.
+func DetectFormat(filename string, format string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".ttl":
+			return turtleFormat, nil
+		case ".trig":
+			return trigFormat, nil
+		case ".nt":
+			return ntriplesFormat, nil
+		default:
+			return nquadsFormat, nil
 		}
 	}
+	switch strings.ToLower(format) {
+	case "nquads", "n-quads", nquadsFormat:
+		return nquadsFormat, nil
+	case "ntriples", "n-triples", ntriplesFormat:
+		return ntriplesFormat, nil
+	case "turtle", "ttl", turtleFormat, "application/x-turtle":
+		return turtleFormat, nil
+	case "trig", trigFormat:
+		return trigFormat, nil
+	default:
+		return "", fmt.Errorf("unsupported RDF format: %v", format)
+	}
 }
 
 func isIRI(k string) bool {
@@ -0,0 +1,107 @@
+package rdf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/functionally/nacatgunma/ipfs"
+)
+
+const testNquads = `<urn:ex:x> <http://example.org/a/description> "1" .
+<urn:ex:x> <http://example.org/b/description> "2" .
+<urn:ex:x> <http://example.org/ns#name> "3" .
+<urn:ex:x> <http://schema.org/name> "4" .
+<urn:ex:x> <urn:ex:opaque> "5" .
+`
+
+func writeTestFile(t *testing.T, name string, content string) string {
+	filename := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(filename, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestGenerateContext(t *testing.T) {
+	rdfFile := writeTestFile(t, "test.nq", testNquads)
+	var first []byte
+	for i := 0; i < 10; i++ {
+		doc, err := ReadRdf(rdfFile, "", "application/n-quads")
+		if err != nil {
+			t.Fatal(err)
+		}
+		bodyBytes, err := ipfs.EncodeToDagCbor(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first == nil {
+			first = bodyBytes
+		} else if !reflect.DeepEqual(first, bodyBytes) {
+			t.Fatal("Body differs between runs")
+		}
+	}
+	doc, _ := ReadRdf(rdfFile, "", "application/n-quads")
+	expected := map[string]interface{}{
+		"descr":  "http://example.org/a/description",
+		"descri": "http://example.org/b/description",
+		"name":   "http://example.org/ns#name",
+	}
+	if !reflect.DeepEqual(doc.(map[string]interface{})["@context"], expected) {
+		t.Errorf("Incorrect generated context: %v", doc.(map[string]interface{})["@context"])
+	}
+}
+
+func TestReadContext(t *testing.T) {
+	rdfFile := writeTestFile(t, "test.nq", testNquads)
+	contextFile := writeTestFile(t, "context.jsonld", `{"@context": {"label": "http://schema.org/name"}}`)
+	context, err := ReadContext(contextFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ReadRdfWithOptions(rdfFile, ReadOptions{
+		Format:  "application/n-quads",
+		Context: context,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.(map[string]interface{})["label"] != "4" {
+		t.Errorf("Fixed context not applied: %v", doc)
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	first := writeTestFile(t, "first.nq", `<urn:ex:x> <http://example.org/knows> _:alice .
+_:alice <http://example.org/name> "Alice" .
+_:alice <http://example.org/knows> _:bob .
+_:bob <http://example.org/name> "Bob" .
+`)
+	second := writeTestFile(t, "second.nq", `_:p2 <http://example.org/name> "Bob" .
+_:p1 <http://example.org/knows> _:p2 .
+<urn:ex:x> <http://example.org/knows> _:p1 .
+_:p1 <http://example.org/name> "Alice" .
+`)
+	encode := func(filename string, canonicalize bool) []byte {
+		doc, err := ReadRdfWithOptions(filename, ReadOptions{
+			Format:       "application/n-quads",
+			Canonicalize: canonicalize,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		bodyBytes, err := ipfs.EncodeToDagCbor(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bodyBytes
+	}
+	if reflect.DeepEqual(encode(first, false), encode(second, false)) {
+		t.Error("Blank node labels did not affect the uncanonicalized body")
+	}
+	if !reflect.DeepEqual(encode(first, true), encode(second, true)) {
+		t.Error("Canonicalized bodies differ")
+	}
+}
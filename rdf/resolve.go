@@ -0,0 +1,133 @@
+package rdf
+
+import (
+	"fmt"
+)
+
+// The policies for resolving conflicting assertions.
+const (
+	LastWriterPolicy   = "last-writer"
+	HighestTrustPolicy = "highest-trust"
+	MajorityPolicy     = "majority"
+)
+
+// Resolution records which assertion won a conflict under a policy, and why.
+type Resolution struct {
+	Subject    string
+	Property   string
+	Winner     Assertion
+	Candidates []Assertion
+	Reason     string
+}
+
+// Trust maps issuer DIDs to weights in [0,1]. Issuers that are not listed have no trust.
+type Trust map[string]float64
+
+func (trust Trust) of(issuer string) float64 {
+	if weight, okay := trust[issuer]; okay {
+		return weight
+	}
+	return trust[issuerIRI(issuer)]
+}
+
+// Resolve settles the conflicts of the functional properties under the policy,
+// returning a state without the losing assertions along with the resolutions.
+// Every block asserting the winning object keeps its assertion. Ties are broken
+// in favour of the latest assertion in DAG order.
+func (state *State) Resolve(properties []string, policy string, trust Trust) (*State, []Resolution, error) {
+	var choose func([]Assertion) (Assertion, string)
+	switch policy {
+	case LastWriterPolicy:
+		choose = chooseLastWriter
+	case HighestTrustPolicy:
+		choose = trust.chooseHighestTrust
+	case MajorityPolicy:
+		choose = trust.chooseMajority
+	default:
+		return nil, nil, fmt.Errorf("unsupported resolution policy: %v", policy)
+	}
+	resolutions := []Resolution{}
+	winners := make(map[[2]string]string)
+	for _, conflict := range state.Conflicts(properties) {
+		winner, reason := choose(conflict.Assertions)
+		resolutions = append(resolutions, Resolution{
+			Subject:    conflict.Subject,
+			Property:   conflict.Property,
+			Winner:     winner,
+			Candidates: conflict.Assertions,
+			Reason:     reason,
+		})
+		winners[[2]string{conflict.Subject, conflict.Property}] = winner.Object
+	}
+	resolved := State{}
+	for i, block := range state.Blocks {
+		kept := block
+		kept.Triples = nil
+		for _, triple := range block.Triples {
+			k := [2]string{state.term(i, triple.Subject), state.term(i, triple.Predicate)}
+			if winner, conflicted := winners[k]; conflicted && state.term(i, triple.Object) != winner {
+				continue
+			}
+			kept.Triples = append(kept.Triples, triple)
+		}
+		resolved.Blocks = append(resolved.Blocks, kept)
+	}
+	return &resolved, resolutions, nil
+}
+
+func chooseLastWriter(assertions []Assertion) (Assertion, string) {
+	latest := assertions[len(assertions)-1]
+	if latest.WithinBlock {
+		return latest, fmt.Sprintf("last of the conflicting assertions within the latest block %v", latest.Block)
+	}
+	return latest, "latest assertion in DAG order"
+}
+
+func (trust Trust) chooseHighestTrust(assertions []Assertion) (Assertion, string) {
+	best := assertions[0]
+	for _, assertion := range assertions[1:] {
+		if trust.of(assertion.Issuer) >= trust.of(best.Issuer) {
+			best = assertion
+		}
+	}
+	return best, fmt.Sprintf("issuer with the highest trust %v", trust.of(best.Issuer))
+}
+
+// chooseMajority counts each issuer DID once per object, weighted by the highest trust
+// of the DID URLs under which it asserted the object.
+func (trust Trust) chooseMajority(assertions []Assertion) (Assertion, string) {
+	issuers := make(map[string]map[string]float64)
+	trusted := make(map[string]bool)
+	latest := make(map[string]Assertion)
+	for _, assertion := range assertions {
+		if issuers[assertion.Object] == nil {
+			issuers[assertion.Object] = make(map[string]float64)
+		}
+		if weight := trust.of(assertion.Issuer); weight > 0 {
+			did := issuerIRI(assertion.Issuer)
+			issuers[assertion.Object][did] = max(issuers[assertion.Object][did], weight)
+			trusted[did] = true
+		}
+		latest[assertion.Object] = assertion
+	}
+	weight := func(object string) float64 {
+		total := 0.0
+		for _, weight := range issuers[object] {
+			total += weight
+		}
+		return total
+	}
+	var best Assertion
+	found := false
+	for _, assertion := range assertions {
+		if latest[assertion.Object].Block != assertion.Block {
+			continue
+		}
+		count, bestCount := len(issuers[assertion.Object]), len(issuers[best.Object])
+		if !found || count > bestCount || (count == bestCount && weight(assertion.Object) >= weight(best.Object)) {
+			best = assertion
+			found = true
+		}
+	}
+	return best, fmt.Sprintf("asserted by %d of %d trusted issuers", len(issuers[best.Object]), len(trusted))
+}
@@ -0,0 +1,85 @@
+package rdf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/piprate/json-gold/ld"
+
+	"github.com/functionally/nacatgunma/ipfs"
+)
+
+func testBlockGraph(t *testing.T, issuer string, name string) BlockGraph {
+	block, err := ipfs.CidV1([]byte(issuer + " " + name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return BlockGraph{
+		Block:  *block,
+		Issuer: issuer,
+		Triples: []*ld.Quad{
+			ld.NewQuad(ld.NewIRI("urn:example:thing"), ld.NewIRI("http://example.org/name"), ld.NewLiteral(name, "", ""), ""),
+		},
+		Ancestors: map[cid.Cid]bool{*block: true},
+	}
+}
+
+func TestResolveMajorityDidURL(t *testing.T) {
+	state := State{
+		Blocks: []BlockGraph{
+			testBlockGraph(t, "did:web:example.org#key-1", "first"),
+			testBlockGraph(t, "did:web:example.org#key-2", "first"),
+			testBlockGraph(t, "did:key:z6MkOther", "second"),
+		},
+	}
+	trust := Trust{
+		"did:web:example.org#key-1": 0.9,
+		"did:key:z6MkOther":         0.3,
+	}
+	_, resolutions, err := state.Resolve([]string{"http://example.org/name"}, MajorityPolicy, trust)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolutions) != 1 {
+		t.Fatalf("Incorrect number of resolutions: %v", len(resolutions))
+	}
+	if resolutions[0].Winner.Object != `"first"` {
+		t.Errorf("Trust of DID URL issuer ignored: %v won because %v", resolutions[0].Winner.Object, resolutions[0].Reason)
+	}
+	if resolutions[0].Reason != "asserted by 1 of 2 trusted issuers" {
+		t.Errorf("Incorrect count of trusted issuers: %v", resolutions[0].Reason)
+	}
+}
+
+func TestConflictWithinBlock(t *testing.T) {
+	earlier := testBlockGraph(t, "did:key:z6MkEarlier", "first")
+	later := testBlockGraph(t, "did:key:z6MkLater", "second")
+	later.Triples = append(later.Triples, ld.NewQuad(ld.NewIRI("urn:example:thing"), ld.NewIRI("http://example.org/name"), ld.NewLiteral("third", "", ""), ""))
+	later.Ancestors[earlier.Block] = true
+	state := State{Blocks: []BlockGraph{earlier, later}}
+	conflicts := state.Conflicts([]string{"http://example.org/name"})
+	if len(conflicts) != 1 || len(conflicts[0].Assertions) != 3 {
+		t.Fatal("Incorrect conflicts")
+	}
+	for _, assertion := range conflicts[0].Assertions {
+		for _, block := range assertion.Supersedes {
+			if block == assertion.Block {
+				t.Errorf("Assertion %v supersedes its own block", assertion.Object)
+			}
+		}
+		if assertion.WithinBlock != (assertion.Block == later.Block) {
+			t.Errorf("Incorrect conflict within block for %v", assertion.Object)
+		}
+		if assertion.Block == later.Block && (len(assertion.Supersedes) != 1 || assertion.Supersedes[0] != earlier.Block) {
+			t.Errorf("Incorrect ancestry for %v: %v", assertion.Object, assertion.Supersedes)
+		}
+	}
+	_, resolutions, err := state.Resolve([]string{"http://example.org/name"}, LastWriterPolicy, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolutions) != 1 || !strings.Contains(resolutions[0].Reason, "within the latest block") {
+		t.Errorf("Conflict within the latest block not reported: %v", resolutions)
+	}
+}
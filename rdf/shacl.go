@@ -0,0 +1,966 @@
+package rdf
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/piprate/json-gold/ld"
+)
+
+const (
+	shaclNamespace = "http://www.w3.org/ns/shacl#"
+	rdfsNamespace  = "http://www.w3.org/2000/01/rdf-schema#"
+	xsdNamespace   = "http://www.w3.org/2001/XMLSchema#"
+	// The depth at which nested shapes are assumed to be recursive.
+	maxShapeDepth = 64
+)
+
+// Result is a single result of SHACL validation.
+type Result struct {
+	Focus      string
+	Path       string `json:",omitempty"`
+	Value      string `json:",omitempty"`
+	Shape      string
+	Constraint string
+	Severity   string
+	Message    string
+}
+
+func (result Result) String() string {
+	details := []string{"focus " + result.Focus}
+	if result.Path != "" {
+		details = append(details, "path "+result.Path)
+	}
+	if result.Value != "" {
+		details = append(details, "value "+result.Value)
+	}
+	details = append(details, "shape "+result.Shape, result.Constraint)
+	return fmt.Sprintf("%v: %v (%v)", result.Severity, result.Message, strings.Join(details, ", "))
+}
+
+// Report is a SHACL validation report. As in SHACL, the data conforms only if there are no results.
+type Report struct {
+	Conforms bool
+	Results  []Result
+}
+
+// Shapes is a SHACL shapes graph.
+type Shapes struct {
+	graph *tripleIndex
+}
+
+// ReadShapes reads a SHACL shapes graph in any of the formats accepted by ReadRdf,
+// detected from the extension of the file.
+func ReadShapes(filename string) (*Shapes, error) {
+	input, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	format, err := DetectFormat(filename, "")
+	if err != nil {
+		return nil, err
+	}
+	var dataset *ld.RDFDataset
+	if format == nquadsFormat {
+		dataset, err = ld.ParseNQuads(string(input))
+	} else {
+		dataset, err = ParseTurtle(input, "", format == trigFormat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid shapes in %v: %w", filename, err)
+	}
+	return &Shapes{graph: newTripleIndex(mergeGraphs(dataset))}, nil
+}
+
+// ShapesCatalog supplies the SHACL shapes for a body according to the schema URI in its header.
+type ShapesCatalog struct {
+	shapes *Shapes
+	files  map[string]string
+	cache  map[string]*Shapes
+}
+
+// NewShapesCatalog applies one shapes graph to the bodies of every schema.
+func NewShapesCatalog(shapes *Shapes) *ShapesCatalog {
+	return &ShapesCatalog{shapes: shapes}
+}
+
+// ReadShapesCatalog reads a JSON object mapping schema URIs to shapes files, which
+// are relative to the directory of the mapping file.
+func ReadShapesCatalog(filename string) (*ShapesCatalog, error) {
+	mapBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var files map[string]string
+	err = json.Unmarshal(mapBytes, &files)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shapes map: %w", err)
+	}
+	for schemaURI, file := range files {
+		if !filepath.IsAbs(file) {
+			files[schemaURI] = filepath.Join(filepath.Dir(filename), file)
+		}
+	}
+	return &ShapesCatalog{
+		files: files,
+		cache: make(map[string]*Shapes),
+	}, nil
+}
+
+// Lookup returns the shapes for a schema URI, or nil if the catalog has none.
+func (catalog *ShapesCatalog) Lookup(schemaURI string) (*Shapes, error) {
+	if catalog.shapes != nil {
+		return catalog.shapes, nil
+	}
+	if shapes, okay := catalog.cache[schemaURI]; okay {
+		return shapes, nil
+	}
+	file, okay := catalog.files[schemaURI]
+	if !okay {
+		return nil, nil
+	}
+	shapes, err := ReadShapes(file)
+	if err != nil {
+		return nil, err
+	}
+	catalog.cache[schemaURI] = shapes
+	return shapes, nil
+}
+
+// ValidateBody validates a decoded JSON-LD body, with relative IRIs resolved against the base URI.
+func (shapes *Shapes) ValidateBody(doc interface{}, baseURI string) (*Report, error) {
+	triples, err := BodyTriples(doc, baseURI)
+	if err != nil {
+		return nil, err
+	}
+	return shapes.Validate(triples)
+}
+
+// Validate validates a data graph against the SHACL Core constraints of the shapes.
+// Constraints outside SHACL Core, such as SPARQL-based ones, are reported as errors
+// rather than ignored.
+func (shapes *Shapes) Validate(triples []*ld.Quad) (*Report, error) {
+	v := validator{
+		shapes: shapes.graph,
+		data:   newTripleIndex(triples),
+	}
+	results := []Result{}
+	for _, shape := range v.shapeNodes() {
+		for _, focus := range v.targets(shape) {
+			shapeResults, err := v.validateShape(shape, focus)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, shapeResults...)
+		}
+	}
+	return &Report{
+		Conforms: len(results) == 0,
+		Results:  results,
+	}, nil
+}
+
+// tripleIndex indexes triples by their formatted subjects, predicates, and objects.
+type tripleIndex struct {
+	count       int
+	bySubject   map[string][]*ld.Quad
+	byPredicate map[string][]*ld.Quad
+	byObject    map[string][]*ld.Quad
+}
+
+func newTripleIndex(triples []*ld.Quad) *tripleIndex {
+	index := tripleIndex{
+		count:       len(triples),
+		bySubject:   make(map[string][]*ld.Quad),
+		byPredicate: make(map[string][]*ld.Quad),
+		byObject:    make(map[string][]*ld.Quad),
+	}
+	for _, triple := range triples {
+		subject := formatTerm(triple.Subject)
+		index.bySubject[subject] = append(index.bySubject[subject], triple)
+		predicate := triple.Predicate.GetValue()
+		index.byPredicate[predicate] = append(index.byPredicate[predicate], triple)
+		object := formatTerm(triple.Object)
+		index.byObject[object] = append(index.byObject[object], triple)
+	}
+	return &index
+}
+
+func (index *tripleIndex) objects(subject ld.Node, predicate string) []ld.Node {
+	var objects []ld.Node
+	for _, triple := range index.bySubject[formatTerm(subject)] {
+		if triple.Predicate.GetValue() == predicate {
+			objects = append(objects, triple.Object)
+		}
+	}
+	return objects
+}
+
+func (index *tripleIndex) object(subject ld.Node, predicate string) ld.Node {
+	objects := index.objects(subject, predicate)
+	if len(objects) == 0 {
+		return nil
+	}
+	return objects[0]
+}
+
+func (index *tripleIndex) subjects(predicate string, object ld.Node) []ld.Node {
+	var subjects []ld.Node
+	for _, triple := range index.byObject[formatTerm(object)] {
+		if triple.Predicate.GetValue() == predicate {
+			subjects = append(subjects, triple.Subject)
+		}
+	}
+	return subjects
+}
+
+func (index *tripleIndex) predicates(subject ld.Node) []string {
+	var predicates []string
+	for _, triple := range index.bySubject[formatTerm(subject)] {
+		predicates = append(predicates, triple.Predicate.GetValue())
+	}
+	return uniqueSorted(predicates)
+}
+
+func (index *tripleIndex) list(head ld.Node) ([]ld.Node, error) {
+	var items []ld.Node
+	for i := 0; head.GetValue() != ld.RDFNil; i++ {
+		first := index.object(head, ld.RDFFirst)
+		rest := index.object(head, ld.RDFRest)
+		if first == nil || rest == nil || i > index.count {
+			return nil, fmt.Errorf("malformed RDF list: %v", formatTerm(head))
+		}
+		items = append(items, first)
+		head = rest
+	}
+	return items, nil
+}
+
+func uniqueSorted(strs []string) []string {
+	sort.Strings(strs)
+	var unique []string
+	for i, s := range strs {
+		if i == 0 || s != strs[i-1] {
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
+
+// uniqueNodes removes repeated nodes, keeping the first occurrence of each.
+func uniqueNodes(nodes []ld.Node) []ld.Node {
+	seen := make(map[string]bool)
+	var unique []ld.Node
+	for _, node := range nodes {
+		key := formatTerm(node)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, node)
+		}
+	}
+	return unique
+}
+
+func sortNodes(nodes []ld.Node) []ld.Node {
+	nodes = uniqueNodes(nodes)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return formatTerm(nodes[i]) < formatTerm(nodes[j])
+	})
+	return nodes
+}
+
+type validator struct {
+	shapes *tripleIndex
+	data   *tripleIndex
+	depth  int
+}
+
+func sh(name string) string {
+	return shaclNamespace + name
+}
+
+// shapeNodes lists the shapes that have targets.
+func (v *validator) shapeNodes() []ld.Node {
+	var shapes []ld.Node
+	for _, predicate := range []string{sh("targetNode"), sh("targetClass"), sh("targetSubjectsOf"), sh("targetObjectsOf")} {
+		for _, triple := range v.shapes.byPredicate[predicate] {
+			shapes = append(shapes, triple.Subject)
+		}
+	}
+	for _, class := range []string{sh("NodeShape"), sh("PropertyShape")} {
+		for _, shape := range v.shapes.subjects(ld.RDFType, ld.NewIRI(class)) {
+			if v.isImplicitClass(shape) {
+				shapes = append(shapes, shape)
+			}
+		}
+	}
+	return sortNodes(shapes)
+}
+
+func (v *validator) isImplicitClass(shape ld.Node) bool {
+	for _, class := range v.shapes.objects(shape, ld.RDFType) {
+		if class.GetValue() == rdfsNamespace+"Class" {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) targets(shape ld.Node) []ld.Node {
+	targets := v.shapes.objects(shape, sh("targetNode"))
+	classes := v.shapes.objects(shape, sh("targetClass"))
+	if v.isImplicitClass(shape) {
+		classes = append(classes, shape)
+	}
+	for _, class := range classes {
+		targets = append(targets, v.instances(class)...)
+	}
+	for _, predicate := range v.shapes.objects(shape, sh("targetSubjectsOf")) {
+		for _, triple := range v.data.byPredicate[predicate.GetValue()] {
+			targets = append(targets, triple.Subject)
+		}
+	}
+	for _, predicate := range v.shapes.objects(shape, sh("targetObjectsOf")) {
+		for _, triple := range v.data.byPredicate[predicate.GetValue()] {
+			targets = append(targets, triple.Object)
+		}
+	}
+	return sortNodes(targets)
+}
+
+// instances finds the nodes of a class or its subclasses in the data graph.
+func (v *validator) instances(class ld.Node) []ld.Node {
+	classes := []ld.Node{class}
+	seen := map[string]bool{formatTerm(class): true}
+	var instances []ld.Node
+	for len(classes) > 0 {
+		current := classes[0]
+		classes = classes[1:]
+		instances = append(instances, v.data.subjects(ld.RDFType, current)...)
+		for _, subclass := range v.data.subjects(rdfsNamespace+"subClassOf", current) {
+			if !seen[formatTerm(subclass)] {
+				seen[formatTerm(subclass)] = true
+				classes = append(classes, subclass)
+			}
+		}
+	}
+	return instances
+}
+
+func (v *validator) isInstance(node ld.Node, class ld.Node) bool {
+	if ld.IsLiteral(node) {
+		return false
+	}
+	classes := v.data.objects(node, ld.RDFType)
+	seen := make(map[string]bool)
+	for len(classes) > 0 {
+		current := classes[0]
+		classes = classes[1:]
+		if current.Equal(class) {
+			return true
+		}
+		if !seen[formatTerm(current)] {
+			seen[formatTerm(current)] = true
+			classes = append(classes, v.data.objects(current, rdfsNamespace+"subClassOf")...)
+		}
+	}
+	return false
+}
+
+func (v *validator) conforms(shape ld.Node, focus ld.Node) (bool, error) {
+	results, err := v.validateShape(shape, focus)
+	return len(results) == 0, err
+}
+
+func (v *validator) isTrue(shape ld.Node, predicate string) bool {
+	value := v.shapes.object(shape, predicate)
+	return value != nil && value.GetValue() == "true"
+}
+
+// validateShape checks the value nodes of a focus node against the constraints of a shape.
+func (v *validator) validateShape(shape ld.Node, focus ld.Node) ([]Result, error) {
+	if v.depth > maxShapeDepth {
+		return nil, fmt.Errorf("recursive shape: %v", formatTerm(shape))
+	}
+	v.depth++
+	defer func() { v.depth-- }()
+	if v.isTrue(shape, sh("deactivated")) {
+		return nil, nil
+	}
+	values := []ld.Node{focus}
+	var pathText string
+	path := v.shapes.object(shape, sh("path"))
+	if path != nil {
+		var err error
+		values, err = v.pathValues(path, focus)
+		if err != nil {
+			return nil, err
+		}
+		values = uniqueNodes(values)
+		pathText, err = v.formatPath(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	severity := "Violation"
+	if node := v.shapes.object(shape, sh("severity")); node != nil {
+		severity = strings.TrimPrefix(node.GetValue(), shaclNamespace)
+	}
+	var message string
+	if node := v.shapes.object(shape, sh("message")); node != nil {
+		message = node.GetValue()
+	}
+	var results []Result
+	for _, predicate := range v.shapes.predicates(shape) {
+		name, okay := strings.CutPrefix(predicate, shaclNamespace)
+		if !okay {
+			continue
+		}
+		fail := func(value ld.Node, defaultMessage string) {
+			result := Result{
+				Focus:      formatTerm(focus),
+				Path:       pathText,
+				Shape:      formatTerm(shape),
+				Constraint: "sh:" + name,
+				Severity:   severity,
+				Message:    message,
+			}
+			if value != nil {
+				result.Value = formatTerm(value)
+			}
+			if result.Message == "" {
+				result.Message = defaultMessage
+			}
+			results = append(results, result)
+		}
+		for _, parameter := range v.shapes.objects(shape, predicate) {
+			nested, err := v.checkConstraint(shape, name, parameter, focus, values, fail)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, nested...)
+		}
+	}
+	return results, nil
+}
+
+// checkConstraint applies one constraint parameter of a shape to the value nodes,
+// reporting failures and returning the results of nested property shapes.
+func (v *validator) checkConstraint(shape ld.Node, name string, parameter ld.Node, focus ld.Node, values []ld.Node, fail func(ld.Node, string)) ([]Result, error) {
+	switch name {
+	case "path", "targetNode", "targetClass", "targetSubjectsOf", "targetObjectsOf", "severity", "message",
+		"deactivated", "name", "description", "order", "group", "defaultValue", "flags", "ignoredProperties",
+		"qualifiedValueShape", "qualifiedValueShapesDisjoint":
+	case "class":
+		for _, value := range values {
+			if !v.isInstance(value, parameter) {
+				fail(value, fmt.Sprintf("Value is not an instance of %v", formatTerm(parameter)))
+			}
+		}
+	case "datatype":
+		for _, value := range values {
+			literal, okay := value.(*ld.Literal)
+			if !okay || literal.Datatype != parameter.GetValue() {
+				fail(value, fmt.Sprintf("Value does not have datatype %v", formatTerm(parameter)))
+			}
+		}
+	case "nodeKind":
+		for _, value := range values {
+			if !nodeKindMatches(value, parameter.GetValue()) {
+				fail(value, fmt.Sprintf("Value is not of node kind %v", formatTerm(parameter)))
+			}
+		}
+	case "minCount", "maxCount":
+		count, err := strconv.Atoi(parameter.GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("invalid sh:%v: %v", name, parameter.GetValue())
+		}
+		if name == "minCount" && len(values) < count {
+			fail(nil, fmt.Sprintf("Fewer than %d values", count))
+		} else if name == "maxCount" && len(values) > count {
+			fail(nil, fmt.Sprintf("More than %d values", count))
+		}
+	case "qualifiedMinCount", "qualifiedMaxCount":
+		count, err := strconv.Atoi(parameter.GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("invalid sh:%v: %v", name, parameter.GetValue())
+		}
+		qualified := v.shapes.object(shape, sh("qualifiedValueShape"))
+		if qualified == nil {
+			return nil, fmt.Errorf("sh:%v without sh:qualifiedValueShape: %v", name, formatTerm(shape))
+		}
+		conforming, err := v.countQualified(shape, qualified, values)
+		if err != nil {
+			return nil, err
+		}
+		if name == "qualifiedMinCount" && conforming < count {
+			fail(nil, fmt.Sprintf("Fewer than %d values conform to shape %v", count, formatTerm(qualified)))
+		} else if name == "qualifiedMaxCount" && conforming > count {
+			fail(nil, fmt.Sprintf("More than %d values conform to shape %v", count, formatTerm(qualified)))
+		}
+	case "minLength", "maxLength":
+		length, err := strconv.Atoi(parameter.GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("invalid sh:%v: %v", name, parameter.GetValue())
+		}
+		for _, value := range values {
+			actual := len([]rune(value.GetValue()))
+			if ld.IsBlankNode(value) || (name == "minLength" && actual < length) || (name == "maxLength" && actual > length) {
+				fail(value, fmt.Sprintf("Value does not satisfy sh:%v %d", name, length))
+			}
+		}
+	case "pattern":
+		pattern := parameter.GetValue()
+		if flags := v.shapes.object(shape, sh("flags")); flags != nil {
+			pattern = "(?" + strings.Map(func(r rune) rune {
+				if strings.ContainsRune("ism", r) {
+					return r
+				}
+				return -1
+			}, flags.GetValue()) + ")" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sh:pattern: %w", err)
+		}
+		for _, value := range values {
+			if ld.IsBlankNode(value) || !re.MatchString(value.GetValue()) {
+				fail(value, fmt.Sprintf("Value does not match pattern %q", parameter.GetValue()))
+			}
+		}
+	case "languageIn":
+		ranges, err := v.shapes.list(parameter)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			if !languageMatches(value, ranges) {
+				fail(value, "Value does not have an allowed language tag")
+			}
+		}
+	case "uniqueLang":
+		if parameter.GetValue() != "true" {
+			break
+		}
+		counts := make(map[string]int)
+		for _, value := range values {
+			if literal, okay := value.(*ld.Literal); okay && literal.Language != "" {
+				counts[strings.ToLower(literal.Language)]++
+			}
+		}
+		var languages []string
+		for language, count := range counts {
+			if count > 1 {
+				languages = append(languages, language)
+			}
+		}
+		sort.Strings(languages)
+		for _, language := range languages {
+			fail(nil, fmt.Sprintf("More than one value with language tag %q", language))
+		}
+	case "in":
+		members, err := v.shapes.list(parameter)
+		if err != nil {
+			return nil, err
+		}
+		allowed := make(map[string]bool)
+		for _, member := range members {
+			allowed[formatTerm(member)] = true
+		}
+		for _, value := range values {
+			if !allowed[formatTerm(value)] {
+				fail(value, "Value is not in the allowed list")
+			}
+		}
+	case "hasValue":
+		found := false
+		for _, value := range values {
+			found = found || value.Equal(parameter)
+		}
+		if !found {
+			fail(nil, fmt.Sprintf("Missing value %v", formatTerm(parameter)))
+		}
+	case "minInclusive", "maxInclusive", "minExclusive", "maxExclusive":
+		for _, value := range values {
+			comparison, okay := compareLiterals(value, parameter)
+			satisfied := okay && map[string]bool{
+				"minInclusive": comparison >= 0,
+				"maxInclusive": comparison <= 0,
+				"minExclusive": comparison > 0,
+				"maxExclusive": comparison < 0,
+			}[name]
+			if !satisfied {
+				fail(value, fmt.Sprintf("Value does not satisfy sh:%v %v", name, formatTerm(parameter)))
+			}
+		}
+	case "lessThan", "lessThanOrEquals":
+		for _, value := range values {
+			for _, other := range v.data.objects(focus, parameter.GetValue()) {
+				comparison, okay := compareLiterals(value, other)
+				if !okay || comparison > 0 || (name == "lessThan" && comparison == 0) {
+					fail(value, fmt.Sprintf("Value does not satisfy sh:%v %v for value %v", name, formatTerm(parameter), formatTerm(other)))
+				}
+			}
+		}
+	case "equals", "disjoint":
+		others := make(map[string]bool)
+		for _, other := range v.data.objects(focus, parameter.GetValue()) {
+			others[formatTerm(other)] = true
+		}
+		current := make(map[string]bool)
+		for _, value := range values {
+			current[formatTerm(value)] = true
+			if name == "equals" && !others[formatTerm(value)] {
+				fail(value, fmt.Sprintf("Value is not a value of %v", formatTerm(parameter)))
+			} else if name == "disjoint" && others[formatTerm(value)] {
+				fail(value, fmt.Sprintf("Value is also a value of %v", formatTerm(parameter)))
+			}
+		}
+		if name == "equals" {
+			for _, other := range v.data.objects(focus, parameter.GetValue()) {
+				if !current[formatTerm(other)] {
+					fail(other, "Value of the compared property is missing")
+				}
+			}
+		}
+	case "closed":
+		if parameter.GetValue() != "true" {
+			break
+		}
+		allowed := make(map[string]bool)
+		for _, property := range v.shapes.objects(shape, sh("property")) {
+			if path := v.shapes.object(property, sh("path")); path != nil && ld.IsIRI(path) {
+				allowed[path.GetValue()] = true
+			}
+		}
+		if ignored := v.shapes.object(shape, sh("ignoredProperties")); ignored != nil {
+			predicates, err := v.shapes.list(ignored)
+			if err != nil {
+				return nil, err
+			}
+			for _, predicate := range predicates {
+				allowed[predicate.GetValue()] = true
+			}
+		}
+		for _, value := range values {
+			for _, triple := range v.data.bySubject[formatTerm(value)] {
+				if !allowed[triple.Predicate.GetValue()] {
+					fail(triple.Object, fmt.Sprintf("Predicate %v is not allowed", formatTerm(triple.Predicate)))
+				}
+			}
+		}
+	case "property":
+		var results []Result
+		for _, value := range values {
+			nested, err := v.validateShape(parameter, value)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, nested...)
+		}
+		return results, nil
+	case "node", "not":
+		for _, value := range values {
+			conforms, err := v.conforms(parameter, value)
+			if err != nil {
+				return nil, err
+			}
+			if name == "node" && !conforms {
+				fail(value, fmt.Sprintf("Value does not conform to shape %v", formatTerm(parameter)))
+			} else if name == "not" && conforms {
+				fail(value, fmt.Sprintf("Value conforms to shape %v", formatTerm(parameter)))
+			}
+		}
+	case "and", "or", "xone":
+		members, err := v.shapes.list(parameter)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			count := 0
+			for _, member := range members {
+				conforms, err := v.conforms(member, value)
+				if err != nil {
+					return nil, err
+				}
+				if conforms {
+					count++
+				}
+			}
+			if (name == "and" && count < len(members)) || (name == "or" && count == 0) || (name == "xone" && count != 1) {
+				fail(value, fmt.Sprintf("Value does not satisfy sh:%v", name))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported SHACL constraint: sh:%v", name)
+	}
+	return nil, nil
+}
+
+// countQualified counts the values that conform to a qualified value shape and, if the
+// qualified value shapes are disjoint, to none of the qualified value shapes of the
+// sibling property shapes.
+func (v *validator) countQualified(shape ld.Node, qualified ld.Node, values []ld.Node) (int, error) {
+	var siblings []ld.Node
+	if v.isTrue(shape, sh("qualifiedValueShapesDisjoint")) {
+		for _, parent := range v.shapes.subjects(sh("property"), shape) {
+			for _, property := range v.shapes.objects(parent, sh("property")) {
+				for _, sibling := range v.shapes.objects(property, sh("qualifiedValueShape")) {
+					if !sibling.Equal(qualified) {
+						siblings = append(siblings, sibling)
+					}
+				}
+			}
+		}
+	}
+	count := 0
+	for _, value := range values {
+		conforms, err := v.conforms(qualified, value)
+		if err != nil {
+			return 0, err
+		}
+		for _, sibling := range uniqueNodes(siblings) {
+			if !conforms {
+				break
+			}
+			conformsSibling, err := v.conforms(sibling, value)
+			if err != nil {
+				return 0, err
+			}
+			conforms = !conformsSibling
+		}
+		if conforms {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func nodeKindMatches(node ld.Node, kind string) bool {
+	var actual string
+	switch {
+	case ld.IsIRI(node):
+		actual = "IRI"
+	case ld.IsBlankNode(node):
+		actual = "BlankNode"
+	default:
+		actual = "Literal"
+	}
+	kinds, _ := strings.CutPrefix(kind, shaclNamespace)
+	return kinds == actual || strings.HasPrefix(kinds, actual+"Or") || strings.HasSuffix(kinds, "Or"+actual)
+}
+
+func languageMatches(node ld.Node, ranges []ld.Node) bool {
+	literal, okay := node.(*ld.Literal)
+	if !okay || literal.Language == "" {
+		return false
+	}
+	language := strings.ToLower(literal.Language)
+	for _, r := range ranges {
+		lower := strings.ToLower(r.GetValue())
+		if lower == "*" || language == lower || strings.HasPrefix(language, lower+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// The XSD datatypes whose literals compare by numeric value.
+var numericDatatypes = map[string]bool{
+	ld.XSDInteger:                       true,
+	ld.XSDDecimal:                       true,
+	ld.XSDDouble:                        true,
+	xsdNamespace + "float":              true,
+	xsdNamespace + "long":               true,
+	xsdNamespace + "int":                true,
+	xsdNamespace + "short":              true,
+	xsdNamespace + "byte":               true,
+	xsdNamespace + "nonNegativeInteger": true,
+	xsdNamespace + "nonPositiveInteger": true,
+	xsdNamespace + "positiveInteger":    true,
+	xsdNamespace + "negativeInteger":    true,
+	xsdNamespace + "unsignedLong":       true,
+	xsdNamespace + "unsignedInt":        true,
+	xsdNamespace + "unsignedShort":      true,
+	xsdNamespace + "unsignedByte":       true,
+}
+
+// The layouts of the XSD date and time datatypes whose literals compare chronologically.
+var temporalLayouts = map[string]string{
+	xsdNamespace + "dateTime": time.RFC3339Nano,
+	xsdNamespace + "date":     time.DateOnly,
+}
+
+// compareLiterals compares two literals with numeric datatypes by value, two strings by
+// code point, or two dates or times of the same datatype chronologically, reporting
+// whether the literals are comparable.
+func compareLiterals(value ld.Node, bound ld.Node) (int, bool) {
+	valueLiteral, okay := value.(*ld.Literal)
+	if !okay {
+		return 0, false
+	}
+	boundLiteral, okay := bound.(*ld.Literal)
+	if !okay {
+		return 0, false
+	}
+	if numericDatatypes[valueLiteral.Datatype] && numericDatatypes[boundLiteral.Datatype] {
+		x, okayX := new(big.Rat).SetString(strings.TrimSpace(valueLiteral.Value))
+		y, okayY := new(big.Rat).SetString(strings.TrimSpace(boundLiteral.Value))
+		if !okayX || !okayY {
+			return 0, false
+		}
+		return x.Cmp(y), true
+	}
+	if valueLiteral.Datatype != boundLiteral.Datatype {
+		return 0, false
+	}
+	if valueLiteral.Datatype == xsdString {
+		return strings.Compare(valueLiteral.Value, boundLiteral.Value), true
+	}
+	if layout, okay := temporalLayouts[valueLiteral.Datatype]; okay {
+		x, errX := time.Parse(layout, valueLiteral.Value)
+		y, errY := time.Parse(layout, boundLiteral.Value)
+		if errX != nil || errY != nil {
+			return 0, false
+		}
+		return x.Compare(y), true
+	}
+	return 0, false
+}
+
+// pathValues follows a SHACL property path from the focus node.
+func (v *validator) pathValues(path ld.Node, focus ld.Node) ([]ld.Node, error) {
+	if ld.IsIRI(path) {
+		return v.data.objects(focus, path.GetValue()), nil
+	}
+	if v.shapes.object(path, ld.RDFFirst) != nil {
+		steps, err := v.shapes.list(path)
+		if err != nil {
+			return nil, err
+		}
+		current := []ld.Node{focus}
+		for _, step := range steps {
+			var next []ld.Node
+			for _, node := range current {
+				values, err := v.pathValues(step, node)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, values...)
+			}
+			current = uniqueNodes(next)
+		}
+		return current, nil
+	}
+	if inverse := v.shapes.object(path, sh("inversePath")); inverse != nil {
+		if !ld.IsIRI(inverse) {
+			return nil, fmt.Errorf("unsupported inverse of a complex path: %v", formatTerm(path))
+		}
+		return v.data.subjects(inverse.GetValue(), focus), nil
+	}
+	if alternatives := v.shapes.object(path, sh("alternativePath")); alternatives != nil {
+		members, err := v.shapes.list(alternatives)
+		if err != nil {
+			return nil, err
+		}
+		var values []ld.Node
+		for _, member := range members {
+			memberValues, err := v.pathValues(member, focus)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, memberValues...)
+		}
+		return uniqueNodes(values), nil
+	}
+	if inner := v.shapes.object(path, sh("zeroOrOnePath")); inner != nil {
+		values, err := v.pathValues(inner, focus)
+		if err != nil {
+			return nil, err
+		}
+		return uniqueNodes(append([]ld.Node{focus}, values...)), nil
+	}
+	if inner := v.shapes.object(path, sh("zeroOrMorePath")); inner != nil {
+		return v.closure(inner, focus, true)
+	}
+	if inner := v.shapes.object(path, sh("oneOrMorePath")); inner != nil {
+		return v.closure(inner, focus, false)
+	}
+	return nil, fmt.Errorf("unsupported SHACL path: %v", formatTerm(path))
+}
+
+func (v *validator) closure(path ld.Node, focus ld.Node, includeFocus bool) ([]ld.Node, error) {
+	var values []ld.Node
+	seen := make(map[string]bool)
+	if includeFocus {
+		values = append(values, focus)
+		seen[formatTerm(focus)] = true
+	}
+	frontier := []ld.Node{focus}
+	for len(frontier) > 0 {
+		next, err := v.pathValues(path, frontier[0])
+		if err != nil {
+			return nil, err
+		}
+		frontier = frontier[1:]
+		for _, node := range next {
+			if !seen[formatTerm(node)] {
+				seen[formatTerm(node)] = true
+				values = append(values, node)
+				frontier = append(frontier, node)
+			}
+		}
+	}
+	return values, nil
+}
+
+// formatPath writes a SHACL property path in SPARQL syntax.
+func (v *validator) formatPath(path ld.Node) (string, error) {
+	if ld.IsIRI(path) {
+		return formatIRI(path.GetValue()), nil
+	}
+	joined := func(list ld.Node, separator string) (string, error) {
+		members, err := v.shapes.list(list)
+		if err != nil {
+			return "", err
+		}
+		var parts []string
+		for _, member := range members {
+			part, err := v.formatPath(member)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, part)
+		}
+		return "(" + strings.Join(parts, separator) + ")", nil
+	}
+	if v.shapes.object(path, ld.RDFFirst) != nil {
+		return joined(path, "/")
+	}
+	if alternatives := v.shapes.object(path, sh("alternativePath")); alternatives != nil {
+		return joined(alternatives, "|")
+	}
+	for predicate, format := range map[string]string{
+		"inversePath":    "^%v",
+		"zeroOrOnePath":  "%v?",
+		"zeroOrMorePath": "%v*",
+		"oneOrMorePath":  "%v+",
+	} {
+		if inner := v.shapes.object(path, sh(predicate)); inner != nil {
+			text, err := v.formatPath(inner)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf(format, text), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported SHACL path: %v", formatTerm(path))
+}
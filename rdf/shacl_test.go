@@ -0,0 +1,229 @@
+package rdf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const testShapes = `@prefix sh: <http://www.w3.org/ns/shacl#> .
+@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .
+@prefix ex: <http://example.org/> .
+
+ex:PersonShape a sh:NodeShape ;
+  sh:targetClass ex:Person ;
+  sh:property [
+    sh:path ex:name ;
+    sh:minCount 1 ; sh:maxCount 1 ;
+    sh:datatype xsd:string ;
+    sh:pattern "^[a-z]" ; sh:flags "i" ;
+  ] ;
+  sh:property [
+    sh:path ex:age ;
+    sh:datatype xsd:integer ;
+    sh:minInclusive 0 ; sh:maxExclusive 150 ;
+  ] ;
+  sh:property [
+    sh:path ex:knows ;
+    sh:class ex:Person ;
+    sh:nodeKind sh:IRI ;
+  ] ;
+  sh:property [
+    sh:path ( ex:knows ex:name ) ;
+    sh:severity sh:Warning ;
+    sh:message "Friends need names" ;
+    sh:minCount 1 ;
+  ] ;
+  sh:property [
+    sh:path [ sh:inversePath ex:knows ] ;
+    sh:maxCount 2 ;
+  ] ;
+  sh:property [
+    sh:path ex:status ;
+    sh:in ( "active" "retired" ) ;
+  ] .
+
+ex:ClosedShape a sh:NodeShape ;
+  sh:targetNode ex:carol ;
+  sh:closed true ;
+  sh:ignoredProperties ( <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> ) ;
+  sh:property [ sh:path ex:name ], [ sh:path ex:knows ] .
+`
+
+func validateTestData(t *testing.T, nquads string) *Report {
+	shapes, err := ReadShapes(writeTestFile(t, "shapes.ttl", testShapes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ReadRdf(writeTestFile(t, "data.nq", nquads), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := shapes.ValidateBody(doc, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestShaclConforms(t *testing.T) {
+	report := validateTestData(t, `<http://example.org/alice> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Person> .
+<http://example.org/alice> <http://example.org/name> "Alice" .
+<http://example.org/alice> <http://example.org/age> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.org/alice> <http://example.org/knows> <http://example.org/carol> .
+<http://example.org/alice> <http://example.org/status> "active" .
+<http://example.org/carol> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Person> .
+<http://example.org/carol> <http://example.org/name> "carol" .
+<http://example.org/carol> <http://example.org/knows> <http://example.org/alice> .
+`)
+	if !report.Conforms {
+		t.Errorf("Valid data does not conform: %v", report.Results)
+	}
+}
+
+func TestShaclViolations(t *testing.T) {
+	report := validateTestData(t, `<http://example.org/alice> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Person> .
+<http://example.org/alice> <http://example.org/name> "Alice" .
+<http://example.org/alice> <http://example.org/name> "9lives" .
+<http://example.org/alice> <http://example.org/age> "150"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.org/alice> <http://example.org/knows> _:bob .
+<http://example.org/alice> <http://example.org/status> "asleep" .
+<http://example.org/carol> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Person> .
+<http://example.org/carol> <http://example.org/name> "Carol" .
+<http://example.org/carol> <http://example.org/nickname> "Caz" .
+`)
+	if report.Conforms {
+		t.Fatal("Invalid data conforms")
+	}
+	var actual []string
+	for _, result := range report.Results {
+		actual = append(actual, result.Severity+" "+result.Focus+" "+result.Path+" "+result.Constraint)
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"Violation <http://example.org/alice> <http://example.org/age> sh:maxExclusive",
+		"Violation <http://example.org/alice> <http://example.org/knows> sh:class",
+		"Violation <http://example.org/alice> <http://example.org/knows> sh:nodeKind",
+		"Violation <http://example.org/alice> <http://example.org/name> sh:maxCount",
+		"Violation <http://example.org/alice> <http://example.org/name> sh:pattern",
+		"Violation <http://example.org/alice> <http://example.org/status> sh:in",
+		"Violation <http://example.org/carol>  sh:closed",
+		"Warning <http://example.org/alice> (<http://example.org/knows>/<http://example.org/name>) sh:minCount",
+		"Warning <http://example.org/carol> (<http://example.org/knows>/<http://example.org/name>) sh:minCount",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Incorrect results:\n%v", report.Results)
+	}
+	for _, result := range report.Results {
+		if result.Severity == "Warning" && result.Message != "Friends need names" {
+			t.Errorf("Custom message not used: %v", result)
+		}
+	}
+}
+
+func TestShaclComparisonsAndQualifiedShapes(t *testing.T) {
+	shapes, err := ReadShapes(writeTestFile(t, "shapes.ttl", `@prefix sh: <http://www.w3.org/ns/shacl#> .
+@prefix ex: <http://example.org/> .
+
+ex:EventShape a sh:NodeShape ;
+  sh:targetClass ex:Event ;
+  sh:property [ sh:path ex:size ; sh:minInclusive 5 ] ;
+  sh:property [ sh:path ex:start ; sh:lessThan ex:end ] ;
+  sh:property [
+    sh:path ex:member ;
+    sh:qualifiedValueShape [ sh:class ex:Chair ] ;
+    sh:qualifiedMinCount 1 ; sh:qualifiedMaxCount 1 ;
+    sh:qualifiedValueShapesDisjoint true ;
+  ] ;
+  sh:property [
+    sh:path ex:member ;
+    sh:qualifiedValueShape [ sh:class ex:Speaker ] ;
+    sh:qualifiedMinCount 1 ;
+    sh:qualifiedValueShapesDisjoint true ;
+  ] .
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	validate := func(nquads string) []string {
+		doc, err := ReadRdf(writeTestFile(t, "data.nq", nquads), "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := shapes.ValidateBody(doc, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual []string
+		for _, result := range report.Results {
+			actual = append(actual, result.Path+" "+result.Constraint)
+		}
+		sort.Strings(actual)
+		return actual
+	}
+	const event = `<http://example.org/e> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Event> .
+<http://example.org/ann> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Chair> .
+<http://example.org/bob> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Speaker> .
+<http://example.org/e> <http://example.org/start> "2024-01-01"^^<http://www.w3.org/2001/XMLSchema#date> .
+`
+	actual := validate(event + `<http://example.org/e> <http://example.org/size> "10"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.org/e> <http://example.org/end> "2024-01-02"^^<http://www.w3.org/2001/XMLSchema#date> .
+<http://example.org/e> <http://example.org/member> <http://example.org/ann> .
+<http://example.org/e> <http://example.org/member> <http://example.org/bob> .
+`)
+	if len(actual) != 0 {
+		t.Errorf("Valid data does not conform: %v", actual)
+	}
+	actual = validate(event + `<http://example.org/e> <http://example.org/size> "10" .
+<http://example.org/e> <http://example.org/end> "2024-01-01"^^<http://www.w3.org/2001/XMLSchema#date> .
+<http://example.org/bob> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Chair> .
+<http://example.org/cat> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Chair> .
+<http://example.org/e> <http://example.org/member> <http://example.org/ann> .
+<http://example.org/e> <http://example.org/member> <http://example.org/bob> .
+<http://example.org/e> <http://example.org/member> <http://example.org/cat> .
+`)
+	expected := []string{
+		"<http://example.org/member> sh:qualifiedMaxCount",
+		"<http://example.org/member> sh:qualifiedMinCount",
+		"<http://example.org/size> sh:minInclusive",
+		"<http://example.org/start> sh:lessThan",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Incorrect results: %v", actual)
+	}
+}
+
+func TestShaclUnsupported(t *testing.T) {
+	shapes, err := ReadShapes(writeTestFile(t, "shapes.ttl", `@prefix sh: <http://www.w3.org/ns/shacl#> .
+<urn:ex:shape> sh:targetNode <urn:ex:x> ; sh:sparql [ ] .
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := shapes.Validate(nil); err == nil {
+		t.Error("Unsupported constraint ignored")
+	}
+}
+
+func TestShapesCatalog(t *testing.T) {
+	shapesFile := writeTestFile(t, "person.ttl", testShapes)
+	mapFile := filepath.Join(filepath.Dir(shapesFile), "shapes.json")
+	err := os.WriteFile(mapFile, []byte(`{"https://example.org/person": "person.ttl"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog, err := ReadShapesCatalog(mapFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shapes, err := catalog.Lookup("https://example.org/person")
+	if err != nil || shapes == nil {
+		t.Fatalf("Shapes not found: %v", err)
+	}
+	shapes, err = catalog.Lookup("https://example.org/other")
+	if err != nil || shapes != nil {
+		t.Errorf("Shapes found for unmapped schema: %v", err)
+	}
+}
@@ -0,0 +1,340 @@
+package rdf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	"github.com/piprate/json-gold/ld"
+
+	"github.com/functionally/nacatgunma/ipfs"
+)
+
+// The schema URI and media types of block bodies holding JSON-LD, either as DAG-CBOR or as JSON.
+// A DAG-CBOR body is JSON-LD if its schema URI is the JSON-LD CBOR schema or, so that the
+// schema URI may name the body's own schema, if its media type has a JSON-LD profile.
+const (
+	JsonLdCborSchema       = "https://w3c.github.io/json-ld-cbor/"
+	DagCborMediaType       = "application/vnd.ipld.dag-cbor"
+	JsonLdMediaType        = "application/ld+json"
+	JsonLdProfile          = "http://www.w3.org/ns/json-ld"
+	DagCborJsonLdMediaType = DagCborMediaType + `; profile="` + JsonLdProfile + `"`
+)
+
+// The reification modes for writing the state graph.
+const (
+	NamedGraphReification = "named-graph"
+	RdfStarReification    = "rdf-star"
+	ProvReification       = "prov"
+)
+
+const (
+	provNamespace      = "http://www.w3.org/ns/prov#"
+	rdfNamespace       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	blockIRIPrefix     = "ipfs://"
+	xsdString          = "http://www.w3.org/2001/XMLSchema#string"
+	rdfLangString      = "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString"
+	blankNodePrefix    = "_:"
+	graphNameSeparator = "#"
+)
+
+// IsRdfBody reports whether a header's schema URI and media type mark its body as JSON-LD.
+func IsRdfBody(schemaURI string, mediaType string) bool {
+	base, params, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return false
+	}
+	switch base {
+	case JsonLdMediaType:
+		return true
+	case DagCborMediaType:
+		// JSON-LD defines profiles, such as "http://www.w3.org/ns/json-ld#compacted", within its namespace.
+		return schemaURI == JsonLdCborSchema || strings.HasPrefix(params["profile"], JsonLdProfile)
+	default:
+		return false
+	}
+}
+
+// BlockGraph holds the triples asserted by the body of a block, along with the
+// block's ancestors in the ledger, including itself.
+type BlockGraph struct {
+	Block     cid.Cid
+	Issuer    string
+	Triples   []*ld.Quad
+	Ancestors map[cid.Cid]bool
+}
+
+// GraphName names the graph of a block's assertions by its issuer's DID and its CID.
+func GraphName(issuer string, block cid.Cid) string {
+	return issuerIRI(issuer) + graphNameSeparator + block.String()
+}
+
+func issuerIRI(issuer string) string {
+	did, _, _ := strings.Cut(issuer, graphNameSeparator)
+	return did
+}
+
+func blockIRI(block cid.Cid) string {
+	return blockIRIPrefix + block.String()
+}
+
+func (graph *BlockGraph) Name() string {
+	return GraphName(graph.Issuer, graph.Block)
+}
+
+// BodyBaseURI is the base URI for the relative IRIs of a body, which resolves them
+// against the body's own CID rather than dropping them.
+func BodyBaseURI(body cid.Cid) string {
+	return blockIRIPrefix + body.String()
+}
+
+// BodyTriples converts a decoded JSON-LD body to RDF triples, with relative IRIs resolved
+// against the base URI, merging any named graphs in the body.
+func BodyTriples(doc interface{}, baseURI string) ([]*ld.Quad, error) {
+	generic, err := normalizeJson(doc)
+	if err != nil {
+		return nil, err
+	}
+	proc := ld.NewJsonLdProcessor()
+	options := ld.NewJsonLdOptions(baseURI)
+	result, err := proc.ToRDF(generic, options)
+	if err != nil {
+		return nil, err
+	}
+	dataset, okay := result.(*ld.RDFDataset)
+	if !okay {
+		return nil, fmt.Errorf("unexpected RDF conversion result: %T", result)
+	}
+	return mergeGraphs(dataset), nil
+}
+
+// mergeGraphs collects the triples of every graph in the dataset, in sorted order.
+func mergeGraphs(dataset *ld.RDFDataset) []*ld.Quad {
+	var graphNames []string
+	for graphName := range dataset.Graphs {
+		graphNames = append(graphNames, graphName)
+	}
+	sort.Strings(graphNames)
+	var triples []*ld.Quad
+	for _, graphName := range graphNames {
+		for _, quad := range dataset.Graphs[graphName] {
+			triples = append(triples, ld.NewQuad(quad.Subject, quad.Predicate, quad.Object, ""))
+		}
+	}
+	sort.SliceStable(triples, func(i, j int) bool {
+		return tripleKey(triples[i]) < tripleKey(triples[j])
+	})
+	return triples
+}
+
+// DecodeBody decodes a JSON-LD body stored with the given media type.
+func DecodeBody(bodyBytes []byte, mediaType string) (interface{}, error) {
+	if base, _, err := mime.ParseMediaType(mediaType); err == nil && base == DagCborMediaType {
+		return ipfs.DecodeFromDagCbor(bodyBytes)
+	}
+	var body interface{}
+	err := json.Unmarshal(bodyBytes, &body)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// normalizeJson converts numbers and other values of a decoded body to the types produced by encoding/json.
+func normalizeJson(doc interface{}) (interface{}, error) {
+	docBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	err = json.Unmarshal(docBytes, &generic)
+	if err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+func tripleKey(triple *ld.Quad) string {
+	return formatTerm(triple.Subject) + " " + formatTerm(triple.Predicate) + " " + formatTerm(triple.Object)
+}
+
+// State is the assembled state graph: the graphs of the visible blocks, ancestors first.
+type State struct {
+	Blocks []BlockGraph
+}
+
+// Write serializes the state with the given reification mode as "nquads", "trig",
+// or "turtle". Named graphs need N-Quads or TriG. RDF-star annotates each asserted
+// triple with its issuer and block, as Turtle-star or as N-Quads-star in the default
+// graph. PROV-O reifies each asserted triple as an rdf:Statement with the same annotations.
+func (state *State) Write(w io.Writer, reification string, format string) error {
+	switch reification + " " + format {
+	case NamedGraphReification + " nquads":
+		return state.WriteNquads(w)
+	case NamedGraphReification + " trig":
+		return state.WriteTrig(w)
+	case RdfStarReification + " nquads":
+		return state.writeRdfStar(w, false)
+	case RdfStarReification + " turtle":
+		return state.writeRdfStar(w, true)
+	case ProvReification + " nquads":
+		return state.writeProv(w, false)
+	case ProvReification + " turtle":
+		return state.writeProv(w, true)
+	default:
+		return fmt.Errorf("unsupported reification %v in format %v", reification, format)
+	}
+}
+
+// WriteNquads writes the state as N-Quads, with each block's triples in its named graph.
+func (state *State) WriteNquads(w io.Writer) error {
+	for i, block := range state.Blocks {
+		graphName := formatIRI(block.Name())
+		for _, triple := range block.Triples {
+			_, err := fmt.Fprintf(w, "%v %v %v %v .\n", state.term(i, triple.Subject), state.term(i, triple.Predicate), state.term(i, triple.Object), graphName)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteTrig writes the state as TriG, with one graph block per visible block.
+func (state *State) WriteTrig(w io.Writer) error {
+	for i, block := range state.Blocks {
+		_, err := fmt.Fprintf(w, "%v {\n", formatIRI(block.Name()))
+		if err != nil {
+			return err
+		}
+		for _, triple := range block.Triples {
+			_, err = fmt.Fprintf(w, "  %v %v %v .\n", state.term(i, triple.Subject), state.term(i, triple.Predicate), state.term(i, triple.Object))
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprint(w, "}\n\n")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (state *State) writeRdfStar(w io.Writer, turtle bool) error {
+	if turtle {
+		_, err := fmt.Fprintf(w, "@prefix prov: %v .\n\n", formatIRI(provNamespace))
+		if err != nil {
+			return err
+		}
+	}
+	for i, block := range state.Blocks {
+		attributed := formatIRI(issuerIRI(block.Issuer))
+		derived := formatIRI(blockIRI(block.Block))
+		for _, triple := range block.Triples {
+			spo := fmt.Sprintf("%v %v %v", state.term(i, triple.Subject), state.term(i, triple.Predicate), state.term(i, triple.Object))
+			var err error
+			if turtle {
+				_, err = fmt.Fprintf(w, "%v {| prov:wasAttributedTo %v ; prov:wasDerivedFrom %v |} .\n", spo, attributed, derived)
+			} else {
+				_, err = fmt.Fprintf(w, "%v .\n<< %v >> %v %v .\n<< %v >> %v %v .\n",
+					spo,
+					spo, formatIRI(provNamespace+"wasAttributedTo"), attributed,
+					spo, formatIRI(provNamespace+"wasDerivedFrom"), derived,
+				)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (state *State) writeProv(w io.Writer, turtle bool) error {
+	if turtle {
+		_, err := fmt.Fprintf(w, "@prefix prov: %v .\n@prefix rdf:  %v .\n\n", formatIRI(provNamespace), formatIRI(rdfNamespace))
+		if err != nil {
+			return err
+		}
+	}
+	for i, block := range state.Blocks {
+		attributed := formatIRI(issuerIRI(block.Issuer))
+		derived := formatIRI(blockIRI(block.Block))
+		for j, triple := range block.Triples {
+			subject := state.term(i, triple.Subject)
+			predicate := state.term(i, triple.Predicate)
+			object := state.term(i, triple.Object)
+			statement := fmt.Sprintf("%vg%vs%v", blankNodePrefix, i, j)
+			var err error
+			if turtle {
+				_, err = fmt.Fprintf(w, "%v %v %v .\n%v a rdf:Statement ; rdf:subject %v ; rdf:predicate %v ; rdf:object %v ;\n  prov:wasAttributedTo %v ; prov:wasDerivedFrom %v .\n",
+					subject, predicate, object,
+					statement, subject, predicate, object,
+					attributed, derived,
+				)
+			} else {
+				lines := [][2]string{
+					{rdfNamespace + "type", formatIRI(rdfNamespace + "Statement")},
+					{rdfNamespace + "subject", subject},
+					{rdfNamespace + "predicate", predicate},
+					{rdfNamespace + "object", object},
+					{provNamespace + "wasAttributedTo", attributed},
+					{provNamespace + "wasDerivedFrom", derived},
+				}
+				_, err = fmt.Fprintf(w, "%v %v %v .\n", subject, predicate, object)
+				for _, line := range lines {
+					if err != nil {
+						break
+					}
+					_, err = fmt.Fprintf(w, "%v %v %v .\n", statement, formatIRI(line[0]), line[1])
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// term formats a node of the i-th block, keeping blank nodes of different blocks distinct.
+func (state *State) term(i int, node ld.Node) string {
+	if ld.IsBlankNode(node) {
+		return fmt.Sprintf("%vg%v%v", blankNodePrefix, i, strings.TrimPrefix(node.GetValue(), blankNodePrefix))
+	}
+	return formatTerm(node)
+}
+
+func formatTerm(node ld.Node) string {
+	switch n := node.(type) {
+	case *ld.IRI:
+		return formatIRI(n.Value)
+	case *ld.BlankNode:
+		return n.Attribute
+	case *ld.Literal:
+		literal := `"` + escapeLiteral(n.Value) + `"`
+		if n.Datatype == rdfLangString {
+			return literal + "@" + n.Language
+		} else if n.Datatype != "" && n.Datatype != xsdString {
+			return literal + "^^" + formatIRI(n.Datatype)
+		}
+		return literal
+	default:
+		return fmt.Sprintf("%v", node)
+	}
+}
+
+func formatIRI(iri string) string {
+	return "<" + iri + ">"
+}
+
+var literalEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func escapeLiteral(s string) string {
+	return literalEscaper.Replace(s)
+}
@@ -0,0 +1,714 @@
+package rdf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/piprate/json-gold/ld"
+)
+
+const defaultGraph = "@default"
+
+// ParseTurtle parses Turtle, or TriG if graphs are allowed, into an RDF dataset.
+// N-Triples is parsed as Turtle. Relative IRIs are resolved against the base URI.
+func ParseTurtle(input []byte, baseURI string, graphs bool) (*ld.RDFDataset, error) {
+	parser := turtleParser{
+		input:    []rune(string(input)),
+		line:     1,
+		base:     baseURI,
+		graphs:   graphs,
+		prefixes: make(map[string]string),
+		labels:   make(map[string]string),
+		dataset:  ld.NewRDFDataset(),
+		graph:    defaultGraph,
+	}
+	err := parser.parseDocument()
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", parser.line, err)
+	}
+	return parser.dataset, nil
+}
+
+type turtleParser struct {
+	input    []rune
+	pos      int
+	line     int
+	base     string
+	graphs   bool
+	prefixes map[string]string
+	labels   map[string]string
+	blanks   int
+	dataset  *ld.RDFDataset
+	graph    string
+}
+
+func (p *turtleParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *turtleParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *turtleParser) peekAt(offset int) rune {
+	if p.pos+offset >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos+offset]
+}
+
+func (p *turtleParser) next() rune {
+	r := p.peek()
+	p.pos++
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+func (p *turtleParser) hasPrefix(s string) bool {
+	runes := []rune(s)
+	if p.pos+len(runes) > len(p.input) {
+		return false
+	}
+	for i, r := range runes {
+		if p.input[p.pos+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// hasKeyword checks for a case-insensitive keyword followed by a delimiter.
+func (p *turtleParser) hasKeyword(keyword string) bool {
+	runes := []rune(keyword)
+	if p.pos+len(runes) > len(p.input) {
+		return false
+	}
+	for i, r := range runes {
+		if unicode.ToLower(p.input[p.pos+i]) != unicode.ToLower(r) {
+			return false
+		}
+	}
+	following := p.peekAt(len(runes))
+	return following == 0 || unicode.IsSpace(following) || strings.ContainsRune("<{[(#", following)
+}
+
+func (p *turtleParser) skip(n int) {
+	for i := 0; i < n; i++ {
+		p.next()
+	}
+}
+
+func (p *turtleParser) skipSpace() {
+	for !p.eof() {
+		r := p.peek()
+		if unicode.IsSpace(r) {
+			p.next()
+		} else if r == '#' {
+			for !p.eof() && p.peek() != '\n' {
+				p.next()
+			}
+		} else {
+			return
+		}
+	}
+}
+
+func (p *turtleParser) expect(r rune) error {
+	p.skipSpace()
+	if p.peek() != r {
+		return p.unexpected(fmt.Sprintf("%q", r))
+	}
+	p.next()
+	return nil
+}
+
+func (p *turtleParser) unexpected(expected string) error {
+	if p.eof() {
+		return fmt.Errorf("expected %v but found end of input", expected)
+	}
+	return fmt.Errorf("expected %v but found %q", expected, p.peek())
+}
+
+func (p *turtleParser) emit(subject ld.Node, predicate ld.Node, object ld.Node) {
+	p.dataset.Graphs[p.graph] = append(p.dataset.Graphs[p.graph], ld.NewQuad(subject, predicate, object, p.graph))
+}
+
+func (p *turtleParser) freshBlank() *ld.BlankNode {
+	node := ld.NewBlankNode(fmt.Sprintf("_:b%d", p.blanks))
+	p.blanks++
+	return node
+}
+
+func (p *turtleParser) parseDocument() error {
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil
+		}
+		switch {
+		case p.hasKeyword("@prefix"):
+			p.skip(len("@prefix"))
+			err := p.parsePrefix(true)
+			if err != nil {
+				return err
+			}
+		case p.hasKeyword("@base"):
+			p.skip(len("@base"))
+			err := p.parseBase(true)
+			if err != nil {
+				return err
+			}
+		case p.hasKeyword("PREFIX"):
+			p.skip(len("PREFIX"))
+			err := p.parsePrefix(false)
+			if err != nil {
+				return err
+			}
+		case p.hasKeyword("BASE"):
+			p.skip(len("BASE"))
+			err := p.parseBase(false)
+			if err != nil {
+				return err
+			}
+		case p.graphs && p.hasKeyword("GRAPH"):
+			p.skip(len("GRAPH"))
+			p.skipSpace()
+			name, err := p.parseGraphName()
+			if err != nil {
+				return err
+			}
+			err = p.parseWrappedGraph(name)
+			if err != nil {
+				return err
+			}
+		case p.graphs && p.peek() == '{':
+			err := p.parseWrappedGraph(defaultGraph)
+			if err != nil {
+				return err
+			}
+		default:
+			err := p.parseTriplesOrGraph()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *turtleParser) parsePrefix(turtleStyle bool) error {
+	p.skipSpace()
+	start := p.pos
+	for !p.eof() && p.peek() != ':' && !unicode.IsSpace(p.peek()) {
+		p.next()
+	}
+	prefix := string(p.input[start:p.pos])
+	err := p.expect(':')
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	iri, err := p.parseIRIRef()
+	if err != nil {
+		return err
+	}
+	p.prefixes[prefix] = iri
+	if turtleStyle {
+		return p.expect('.')
+	}
+	return nil
+}
+
+func (p *turtleParser) parseBase(turtleStyle bool) error {
+	p.skipSpace()
+	iri, err := p.parseIRIRef()
+	if err != nil {
+		return err
+	}
+	p.base = iri
+	if turtleStyle {
+		return p.expect('.')
+	}
+	return nil
+}
+
+func (p *turtleParser) parseGraphName() (string, error) {
+	switch {
+	case p.peek() == '[':
+		p.next()
+		err := p.expect(']')
+		if err != nil {
+			return "", err
+		}
+		return p.freshBlank().Attribute, nil
+	case p.hasPrefix("_:"):
+		node, err := p.parseBlankNodeLabel()
+		if err != nil {
+			return "", err
+		}
+		return node.Attribute, nil
+	default:
+		node, err := p.parseIRI()
+		if err != nil {
+			return "", err
+		}
+		return node.Value, nil
+	}
+}
+
+// parseWrappedGraph parses the triples between braces into the named graph.
+func (p *turtleParser) parseWrappedGraph(name string) error {
+	err := p.expect('{')
+	if err != nil {
+		return err
+	}
+	p.graph = name
+	defer func() { p.graph = defaultGraph }()
+	for {
+		p.skipSpace()
+		if p.peek() == '}' {
+			p.next()
+			return nil
+		}
+		err = p.parseTriples()
+		if err != nil {
+			return err
+		}
+		p.skipSpace()
+		if p.peek() == '.' {
+			p.next()
+		} else if p.peek() != '}' {
+			return p.unexpected("'.' or '}'")
+		}
+	}
+}
+
+// parseTriplesOrGraph parses a statement at the top level, which in TriG may name a graph.
+func (p *turtleParser) parseTriplesOrGraph() error {
+	if p.graphs && (p.peek() == '<' || p.hasPrefix("_:") || p.peek() == '[' || p.isPrefixedNameStart()) {
+		start, line := p.pos, p.line
+		name, err := p.parseGraphName()
+		if err == nil {
+			p.skipSpace()
+			if p.peek() == '{' {
+				return p.parseWrappedGraph(name)
+			}
+		}
+		p.pos, p.line = start, line
+	}
+	err := p.parseTriples()
+	if err != nil {
+		return err
+	}
+	return p.expect('.')
+}
+
+func (p *turtleParser) parseTriples() error {
+	p.skipSpace()
+	if p.peek() == '[' {
+		subject, err := p.parseBlankNodePropertyList()
+		if err != nil {
+			return err
+		}
+		p.skipSpace()
+		if p.peek() == '.' || p.peek() == '}' {
+			return nil
+		}
+		return p.parsePredicateObjectList(subject)
+	}
+	subject, err := p.parseSubject()
+	if err != nil {
+		return err
+	}
+	return p.parsePredicateObjectList(subject)
+}
+
+func (p *turtleParser) parseSubject() (ld.Node, error) {
+	switch {
+	case p.hasPrefix("_:"):
+		return p.parseBlankNodeLabel()
+	case p.peek() == '(':
+		return p.parseCollection()
+	default:
+		return p.parseIRI()
+	}
+}
+
+func (p *turtleParser) parsePredicateObjectList(subject ld.Node) error {
+	for {
+		p.skipSpace()
+		predicate, err := p.parseVerb()
+		if err != nil {
+			return err
+		}
+		for {
+			object, err := p.parseObject()
+			if err != nil {
+				return err
+			}
+			p.emit(subject, predicate, object)
+			p.skipSpace()
+			if p.peek() != ',' {
+				break
+			}
+			p.next()
+		}
+		if p.peek() != ';' {
+			return nil
+		}
+		for p.peek() == ';' {
+			p.next()
+			p.skipSpace()
+		}
+		if p.eof() || strings.ContainsRune(".]}", p.peek()) {
+			return nil
+		}
+	}
+}
+
+func (p *turtleParser) parseVerb() (ld.Node, error) {
+	if p.peek() == 'a' {
+		following := p.peekAt(1)
+		if unicode.IsSpace(following) || strings.ContainsRune("<[(\"'_", following) {
+			p.next()
+			return ld.NewIRI(ld.RDFType), nil
+		}
+	}
+	return p.parseIRI()
+}
+
+func (p *turtleParser) parseObject() (ld.Node, error) {
+	p.skipSpace()
+	r := p.peek()
+	switch {
+	case p.hasPrefix("_:"):
+		return p.parseBlankNodeLabel()
+	case r == '[':
+		return p.parseBlankNodePropertyList()
+	case r == '(':
+		return p.parseCollection()
+	case r == '"' || r == '\'':
+		return p.parseLiteral()
+	case r == '+' || r == '-' || r == '.' || unicode.IsDigit(r):
+		return p.parseNumber()
+	case p.isBoolean("true") || p.isBoolean("false"):
+		value := "true"
+		if r == 'f' {
+			value = "false"
+		}
+		p.skip(len(value))
+		return ld.NewLiteral(value, ld.XSDBoolean, ""), nil
+	default:
+		return p.parseIRI()
+	}
+}
+
+func (p *turtleParser) isBoolean(value string) bool {
+	if !p.hasPrefix(value) {
+		return false
+	}
+	following := p.peekAt(len(value))
+	return following == 0 || unicode.IsSpace(following) || strings.ContainsRune(",;.)]}#", following)
+}
+
+func (p *turtleParser) parseBlankNodePropertyList() (ld.Node, error) {
+	err := p.expect('[')
+	if err != nil {
+		return nil, err
+	}
+	node := p.freshBlank()
+	p.skipSpace()
+	if p.peek() == ']' {
+		p.next()
+		return node, nil
+	}
+	err = p.parsePredicateObjectList(node)
+	if err != nil {
+		return nil, err
+	}
+	return node, p.expect(']')
+}
+
+func (p *turtleParser) parseCollection() (ld.Node, error) {
+	err := p.expect('(')
+	if err != nil {
+		return nil, err
+	}
+	var items []ld.Node
+	for {
+		p.skipSpace()
+		if p.peek() == ')' {
+			p.next()
+			break
+		}
+		if p.eof() {
+			return nil, p.unexpected("')'")
+		}
+		item, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return ld.NewIRI(ld.RDFNil), nil
+	}
+	head := p.freshBlank()
+	current := head
+	for i, item := range items {
+		p.emit(current, ld.NewIRI(ld.RDFFirst), item)
+		if i == len(items)-1 {
+			p.emit(current, ld.NewIRI(ld.RDFRest), ld.NewIRI(ld.RDFNil))
+		} else {
+			rest := p.freshBlank()
+			p.emit(current, ld.NewIRI(ld.RDFRest), rest)
+			current = rest
+		}
+	}
+	return head, nil
+}
+
+func (p *turtleParser) parseBlankNodeLabel() (*ld.BlankNode, error) {
+	p.skip(2)
+	start := p.pos
+	for !p.eof() && isNameRune(p.peek()) {
+		p.next()
+	}
+	for p.pos > start && p.input[p.pos-1] == '.' {
+		p.pos--
+	}
+	if p.pos == start {
+		return nil, p.unexpected("blank node label")
+	}
+	label := string(p.input[start:p.pos])
+	if _, okay := p.labels[label]; !okay {
+		p.labels[label] = p.freshBlank().Attribute
+	}
+	return ld.NewBlankNode(p.labels[label]), nil
+}
+
+func (p *turtleParser) isPrefixedNameStart() bool {
+	r := p.peek()
+	return r == ':' || unicode.IsLetter(r)
+}
+
+func (p *turtleParser) parseIRI() (*ld.IRI, error) {
+	p.skipSpace()
+	if p.peek() == '<' {
+		iri, err := p.parseIRIRef()
+		if err != nil {
+			return nil, err
+		}
+		return ld.NewIRI(iri), nil
+	}
+	if !p.isPrefixedNameStart() {
+		return nil, p.unexpected("IRI")
+	}
+	start := p.pos
+	for !p.eof() && p.peek() != ':' && isNameRune(p.peek()) {
+		p.next()
+	}
+	prefix := string(p.input[start:p.pos])
+	if p.peek() != ':' {
+		return nil, fmt.Errorf("expected prefixed name but found %q", prefix)
+	}
+	p.next()
+	namespace, okay := p.prefixes[prefix]
+	if !okay {
+		return nil, fmt.Errorf("undefined prefix %q", prefix)
+	}
+	var local strings.Builder
+	for !p.eof() {
+		r := p.peek()
+		if r == '\\' {
+			p.next()
+			local.WriteRune(p.next())
+		} else if r == '.' && !isNameRune(p.peekAt(1)) {
+			break
+		} else if isNameRune(r) || r == ':' || r == '%' {
+			local.WriteRune(p.next())
+		} else {
+			break
+		}
+	}
+	return ld.NewIRI(namespace + local.String()), nil
+}
+
+func (p *turtleParser) parseIRIRef() (string, error) {
+	if p.peek() != '<' {
+		return "", p.unexpected("'<'")
+	}
+	p.next()
+	var iri strings.Builder
+	for {
+		if p.eof() {
+			return "", p.unexpected("'>'")
+		}
+		r := p.next()
+		if r == '>' {
+			break
+		} else if r == '\\' {
+			decoded, err := p.parseUnicodeEscape()
+			if err != nil {
+				return "", err
+			}
+			iri.WriteRune(decoded)
+		} else if unicode.IsSpace(r) {
+			return "", fmt.Errorf("whitespace in IRI")
+		} else {
+			iri.WriteRune(r)
+		}
+	}
+	return p.resolve(iri.String()), nil
+}
+
+func (p *turtleParser) resolve(iri string) string {
+	if p.base == "" || ld.IsAbsoluteIri(iri) {
+		return iri
+	}
+	return ld.Resolve(p.base, iri)
+}
+
+func (p *turtleParser) parseUnicodeEscape() (rune, error) {
+	var digits int
+	switch p.next() {
+	case 'u':
+		digits = 4
+	case 'U':
+		digits = 8
+	default:
+		return 0, fmt.Errorf("invalid escape in IRI")
+	}
+	if p.pos+digits > len(p.input) {
+		return 0, fmt.Errorf("truncated unicode escape")
+	}
+	code, err := strconv.ParseUint(string(p.input[p.pos:p.pos+digits]), 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid unicode escape: %w", err)
+	}
+	p.skip(digits)
+	return rune(code), nil
+}
+
+func (p *turtleParser) parseLiteral() (ld.Node, error) {
+	quote := p.next()
+	long := p.peek() == quote && p.peekAt(1) == quote
+	if long {
+		p.skip(2)
+	}
+	var value strings.Builder
+	for {
+		if p.eof() {
+			return nil, p.unexpected("end of string")
+		}
+		r := p.peek()
+		if r == quote {
+			if !long {
+				p.next()
+				break
+			}
+			if p.peekAt(1) == quote && p.peekAt(2) == quote {
+				p.skip(3)
+				break
+			}
+			value.WriteRune(p.next())
+		} else if r == '\\' {
+			p.next()
+			switch escaped := p.peek(); escaped {
+			case 'u', 'U':
+				decoded, err := p.parseUnicodeEscape()
+				if err != nil {
+					return nil, err
+				}
+				value.WriteRune(decoded)
+			case 't':
+				p.next()
+				value.WriteRune('\t')
+			case 'b':
+				p.next()
+				value.WriteRune('\b')
+			case 'n':
+				p.next()
+				value.WriteRune('\n')
+			case 'r':
+				p.next()
+				value.WriteRune('\r')
+			case 'f':
+				p.next()
+				value.WriteRune('\f')
+			case '"', '\'', '\\':
+				value.WriteRune(p.next())
+			default:
+				return nil, fmt.Errorf("invalid escape %q in string", escaped)
+			}
+		} else if !long && (r == '\n' || r == '\r') {
+			return nil, fmt.Errorf("line break in string")
+		} else {
+			value.WriteRune(p.next())
+		}
+	}
+	if p.peek() == '@' {
+		p.next()
+		start := p.pos
+		for !p.eof() && (unicode.IsLetter(p.peek()) || unicode.IsDigit(p.peek()) || p.peek() == '-') {
+			p.next()
+		}
+		return ld.NewLiteral(value.String(), ld.RDFLangString, strings.ToLower(string(p.input[start:p.pos]))), nil
+	}
+	if p.hasPrefix("^^") {
+		p.skip(2)
+		datatype, err := p.parseIRI()
+		if err != nil {
+			return nil, err
+		}
+		return ld.NewLiteral(value.String(), datatype.Value, ""), nil
+	}
+	return ld.NewLiteral(value.String(), "", ""), nil
+}
+
+func (p *turtleParser) parseNumber() (ld.Node, error) {
+	start := p.pos
+	if p.peek() == '+' || p.peek() == '-' {
+		p.next()
+	}
+	digits := func() int {
+		n := 0
+		for unicode.IsDigit(p.peek()) {
+			p.next()
+			n++
+		}
+		return n
+	}
+	datatype := ld.XSDInteger
+	whole := digits()
+	fraction := 0
+	if p.peek() == '.' && unicode.IsDigit(p.peekAt(1)) {
+		p.next()
+		fraction = digits()
+		datatype = ld.XSDDecimal
+	}
+	if whole == 0 && fraction == 0 {
+		return nil, p.unexpected("number")
+	}
+	if p.peek() == 'e' || p.peek() == 'E' {
+		p.next()
+		if p.peek() == '+' || p.peek() == '-' {
+			p.next()
+		}
+		if digits() == 0 {
+			return nil, p.unexpected("exponent")
+		}
+		datatype = ld.XSDDouble
+	}
+	return ld.NewLiteral(string(p.input[start:p.pos]), datatype, ""), nil
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' || r == '·'
+}
@@ -0,0 +1,133 @@
+package rdf
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/piprate/json-gold/ld"
+)
+
+func datasetLines(t *testing.T, dataset *ld.RDFDataset) []string {
+	serializer := &ld.NQuadRDFSerializer{}
+	nquads, err := serializer.Serialize(dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(nquads.(string)), "\n")
+	sort.Strings(lines)
+	return lines
+}
+
+func checkLines(t *testing.T, actual []string, expected []string) {
+	sort.Strings(expected)
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Incorrect quads:\n%v\nexpected:\n%v", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestParseTurtle(t *testing.T) {
+	dataset, err := ParseTurtle([]byte(`
+@base <http://example.org/> .
+@prefix ex: <http://example.org/ns#> .
+PREFIX xsd: <http://www.w3.org/2001/XMLSchema#>
+
+# A comment.
+<alice> a ex:Person ;
+  ex:name "Alice"@EN, 'Alicia' ;
+  ex:age 42 ;
+  ex:height 1.7 ;
+  ex:mass 6.5e1 ;
+  ex:active true;
+  ex:born "2000-01-01"^^xsd:date ;
+  ex:note """two
+"lines!\"""" ;
+  ex:knows [ ex:name "Bob" ], _:carol ;
+  ex:likes ( ex:tea "coffee" ) ;
+  ex:empty () ;
+.
+_:carol ex:name "Carol\t" .
+ex:x\.y ex:ref <#frag> .
+`), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLines(t, datasetLines(t, dataset), []string{
+		`<http://example.org/alice> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/ns#Person> .`,
+		`<http://example.org/alice> <http://example.org/ns#name> "Alice"@en .`,
+		`<http://example.org/alice> <http://example.org/ns#name> "Alicia" .`,
+		`<http://example.org/alice> <http://example.org/ns#age> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .`,
+		`<http://example.org/alice> <http://example.org/ns#height> "1.7"^^<http://www.w3.org/2001/XMLSchema#decimal> .`,
+		`<http://example.org/alice> <http://example.org/ns#mass> "6.5e1"^^<http://www.w3.org/2001/XMLSchema#double> .`,
+		`<http://example.org/alice> <http://example.org/ns#active> "true"^^<http://www.w3.org/2001/XMLSchema#boolean> .`,
+		`<http://example.org/alice> <http://example.org/ns#born> "2000-01-01"^^<http://www.w3.org/2001/XMLSchema#date> .`,
+		`<http://example.org/alice> <http://example.org/ns#note> "two\n\"lines!\"" .`,
+		`<http://example.org/alice> <http://example.org/ns#knows> _:b0 .`,
+		`_:b0 <http://example.org/ns#name> "Bob" .`,
+		`<http://example.org/alice> <http://example.org/ns#knows> _:b1 .`,
+		`<http://example.org/alice> <http://example.org/ns#likes> _:b2 .`,
+		`_:b2 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> <http://example.org/ns#tea> .`,
+		`_:b2 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> _:b3 .`,
+		`_:b3 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> "coffee" .`,
+		`_:b3 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .`,
+		`<http://example.org/alice> <http://example.org/ns#empty> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .`,
+		`_:b1 <http://example.org/ns#name> "Carol\t" .`,
+		`<http://example.org/ns#x.y> <http://example.org/ns#ref> <http://example.org/#frag> .`,
+	})
+}
+
+func TestParseTrig(t *testing.T) {
+	dataset, err := ParseTurtle([]byte(`
+@prefix ex: <http://example.org/ns#> .
+ex:a ex:p 1 .
+{ ex:b ex:p 2 }
+ex:g { ex:c ex:p 3 . ex:d ex:p 4 }
+GRAPH <http://example.org/h> { ex:e ex:p ex:f . }
+`), "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLines(t, datasetLines(t, dataset), []string{
+		`<http://example.org/ns#a> <http://example.org/ns#p> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .`,
+		`<http://example.org/ns#b> <http://example.org/ns#p> "2"^^<http://www.w3.org/2001/XMLSchema#integer> .`,
+		`<http://example.org/ns#c> <http://example.org/ns#p> "3"^^<http://www.w3.org/2001/XMLSchema#integer> <http://example.org/ns#g> .`,
+		`<http://example.org/ns#d> <http://example.org/ns#p> "4"^^<http://www.w3.org/2001/XMLSchema#integer> <http://example.org/ns#g> .`,
+		`<http://example.org/ns#e> <http://example.org/ns#p> <http://example.org/ns#f> <http://example.org/h> .`,
+	})
+}
+
+func TestParseTurtleErrors(t *testing.T) {
+	for _, input := range []string{
+		`<urn:ex:a> <urn:ex:p> "unterminated .`,
+		`undefined:a <urn:ex:p> 1 .`,
+		`<urn:ex:a> <urn:ex:p> 1`,
+		`<urn:ex:a> <urn:ex:p> [ <urn:ex:q> 2 .`,
+	} {
+		if _, err := ParseTurtle([]byte(input), "", false); err == nil {
+			t.Errorf("Invalid Turtle accepted: %v", input)
+		}
+	}
+}
+
+func TestReadTurtle(t *testing.T) {
+	turtle := writeTestFile(t, "test.ttl", `@prefix s: <http://schema.org/> .
+<urn:ex:x> s:name "4" .
+`)
+	nquads := writeTestFile(t, "test.nq", `<urn:ex:x> <http://schema.org/name> "4" .
+`)
+	fromTurtle, err := ReadRdf(turtle, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromNquads, err := ReadRdf(nquads, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromTurtle, fromNquads) {
+		t.Errorf("Turtle and N-Quads differ: %v and %v", fromTurtle, fromNquads)
+	}
+	if _, err := DetectFormat(turtle, "rdf/xml"); err == nil {
+		t.Error("Unsupported format accepted")
+	}
+}